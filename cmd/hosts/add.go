@@ -0,0 +1,76 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"net"
+	"strings"
+)
+
+var addCommand = &command{
+	name:    "add",
+	usage:   "[-ttl DURATION] [-tag TAG,...] [-comment TEXT] IP HOSTNAME...",
+	summary: "add an entry",
+	run:     runAdd,
+}
+
+func runAdd(env *environment, args []string) error {
+	flags := newFlagSet("add", env)
+	ttl := flags.Duration("ttl", 0, "remove the entry after the given duration, e.g. 2h")
+	comment := flags.String("comment", "", "inline comment of the entry")
+	tags := flags.String("tag", "", "comma separated tags of the entry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 || *ttl < 0 {
+		return errorUsage
+	}
+
+	ip := net.ParseIP(flags.Arg(0))
+	if ip == nil {
+		return hosts.ErrorInvalidIp
+	}
+	entry, err := hosts.NewEntry(ip, flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	entry.SetComment(*comment)
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag == "" {
+			continue
+		}
+		if err := entry.AddTag(tag); err != nil {
+			return err
+		}
+	}
+	if *ttl > 0 {
+		entry.SetExpiresAt(env.clock.Now().Add(*ttl))
+	}
+
+	return editDocument(env, "add "+strings.Join(args, " "), func(doc *parser.Document) error {
+		_, err := doc.Add(entry)
+		return err
+	})
+}
@@ -0,0 +1,126 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/internal/diff"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+var errorBackupsDisabled = errors.New("backups are disabled")
+
+var backupCommand = &command{
+	name:    "backup",
+	usage:   "list",
+	summary: "list the backups of the hosts file",
+	run:     runBackup,
+}
+
+var restoreCommand = &command{
+	name:    "restore",
+	usage:   "[-y] ID",
+	summary: "restore a backup of the hosts file",
+	run:     runRestore,
+}
+
+func backupStore(env *environment) *hostsfile.BackupStore {
+	return &hostsfile.BackupStore{
+		Dir:      env.backup,
+		MaxCount: defaultBackupCount,
+		MaxAge:   defaultBackupMaxAge,
+		Clock:    env.clock,
+	}
+}
+
+func runBackup(env *environment, args []string) error {
+	flags := newFlagSet("backup", env)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || flags.Arg(0) != "list" {
+		return errorUsage
+	}
+	if env.backup == "" {
+		return errorBackupsDisabled
+	}
+
+	backups, err := backupStore(env).List(env.file)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		fmt.Fprintf(env.stdout, "%s  %s  %d bytes\n", backup.ID, backup.Time.Format(time.RFC3339), backup.Size)
+	}
+	return nil
+}
+
+func runRestore(env *environment, args []string) error {
+	flags := newFlagSet("restore", env)
+	yes := flags.Bool("y", false, "restore without asking for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errorUsage
+	}
+	if env.backup == "" {
+		return errorBackupsDisabled
+	}
+
+	store := backupStore(env)
+	backup, content, err := store.Get(env.file, flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %v", flags.Arg(0), err)
+	}
+	current, err := ioutil.ReadFile(env.file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	edits := diff.Lines(diff.SplitLines(current), diff.SplitLines(content))
+	if !diff.Changed(edits) {
+		fmt.Fprintf(env.stdout, "%s is already equal to backup %s\n", env.file, backup.ID)
+		return nil
+	}
+	fmt.Fprint(env.stdout, diff.Unified(edits, env.file, backup.ID, 3))
+
+	if !*yes && !confirm(env, fmt.Sprintf("restore %s from backup %s?", env.file, backup.ID)) {
+		return errors.New("aborted")
+	}
+	return store.Restore(backup.ID, env.file)
+}
+
+// confirm asks the question on stdout and reports whether the answer read from stdin is yes.
+func confirm(env *environment, question string) bool {
+	fmt.Fprintf(env.stdout, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(env.stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,63 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+)
+
+var compileCommand = &command{
+	name:    "compile",
+	usage:   "[-base PATH] [-dir DIR] [-v]",
+	summary: "build the hosts file from a base file and a drop-in directory",
+	run:     runCompile,
+}
+
+func runCompile(env *environment, args []string) error {
+	flags := newFlagSet("compile", env)
+	base := flags.String("base", "/etc/hosts.base", "path of the base file")
+	dir := flags.String("dir", "/etc/hosts.d", "drop-in directory of the fragments ("+hostsfile.FragmentPattern+")")
+	verbose := flags.Bool("v", false, "report whether the hosts file changed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errorUsage
+	}
+
+	compiler := &hostsfile.Compiler{Base: *base, Dir: *dir}
+	changed, err := compiler.CompileTo(env.file)
+	if err != nil {
+		return err
+	}
+
+	if *verbose {
+		if changed {
+			fmt.Fprintf(env.stdout, "%s updated\n", env.file)
+		} else {
+			fmt.Fprintf(env.stdout, "%s unchanged\n", env.file)
+		}
+	}
+	return nil
+}
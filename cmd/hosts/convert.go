@@ -0,0 +1,70 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts/codec"
+	"io"
+	"os"
+	"strings"
+)
+
+var convertCommand = &command{
+	name:    "convert",
+	usage:   "[-from FORMAT] [-to FORMAT] [FILE | -]",
+	summary: "convert the hosts file or FILE (- for stdin) into another format",
+	run:     runConvert,
+}
+
+func runConvert(env *environment, args []string) error {
+	flags := newFlagSet("convert", env)
+	formats := strings.Join(codec.Names(), ", ")
+	from := flags.String("from", codec.Auto, fmt.Sprintf("format of the input: %s or %s", codec.Auto, formats))
+	to := flags.String("to", codec.Hosts, "format of the output: "+formats)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return errorUsage
+	}
+
+	var input io.Reader = env.stdin
+	if path := flags.Arg(0); path != "-" {
+		if path == "" {
+			path = env.file
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	entrySet, err := codec.Decode(*from, input)
+	if err != nil {
+		return err
+	}
+	return codec.Encode(*to, entrySet, env.stdout)
+}
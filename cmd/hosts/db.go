@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts/hostsdb"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"net"
+	"strings"
+)
+
+var errorIPNotFound = errors.New("ip not found")
+
+var compileDBCommand = &command{
+	name:    "compile-db",
+	usage:   "[-o PATH]",
+	summary: "compile the hosts file into a binary lookup database",
+	run:     runCompileDB,
+}
+
+var lookupCommand = &command{
+	name:    "lookup",
+	usage:   "[-db PATH] HOSTNAME | IP",
+	summary: "look up a host name or ip in a compiled database",
+	run:     runLookup,
+}
+
+func runCompileDB(env *environment, args []string) error {
+	flags := newFlagSet("compile-db", env)
+	output := flags.String("o", defaultDatabase, "path of the database")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errorUsage
+	}
+
+	entrySet, err := hostsfile.Read(env.file)
+	if err != nil {
+		return err
+	}
+	return hostsdb.WriteFile(entrySet, *output)
+}
+
+func runLookup(env *environment, args []string) error {
+	flags := newFlagSet("lookup", env)
+	path := flags.String("db", defaultDatabase, "path of the database")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errorUsage
+	}
+
+	db, err := hostsdb.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if ip := net.ParseIP(flags.Arg(0)); ip != nil {
+		hostNames, ok := db.EntriesOfIP(ip)
+		if !ok {
+			return fmt.Errorf("%s: %v", ip, errorIPNotFound)
+		}
+		fmt.Fprintln(env.stdout, strings.Join(hostNames, " "))
+		return nil
+	}
+	ips, ok := db.LookupHost(flags.Arg(0))
+	if !ok {
+		return fmt.Errorf("%s: %v", flags.Arg(0), parser.HostNameNotFoundError)
+	}
+	for _, ip := range ips {
+		fmt.Fprintln(env.stdout, ip)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bitofcode/hosts/internal/diff"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+)
+
+var fmtCommand = &command{
+	name:    "fmt",
+	usage:   "[-l] [-d] [-w] [-check] [FILE...]",
+	summary: "rewrite hosts files in the canonical layout",
+	run:     runFmt,
+}
+
+func runFmt(env *environment, args []string) error {
+	flags := newFlagSet("fmt", env)
+	list := flags.Bool("l", false, "list the files whose layout is not canonical")
+	showDiff := flags.Bool("d", false, "print the differences to the canonical layout")
+	write := flags.Bool("w", false, "write the canonical layout back to the files")
+	check := flags.Bool("check", false, "fail if a file is not in the canonical layout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{env.file}
+	}
+
+	unformatted := 0
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		doc, err := parser.ReadDocument(bytes.NewReader(src))
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		doc.Format()
+		formatted := []byte(doc.String())
+
+		if !*list && !*showDiff && !*write && !*check {
+			if _, err := env.stdout.Write(formatted); err != nil {
+				return err
+			}
+			continue
+		}
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		unformatted++
+		if *list {
+			fmt.Fprintln(env.stdout, file)
+		}
+		if *showDiff {
+			edits := diff.Lines(diff.SplitLines(src), diff.SplitLines(formatted))
+			fmt.Fprint(env.stdout, diff.Unified(edits, file, file+" (formatted)", 3))
+		}
+		if *write {
+			err := editFile(env, file, "fmt -w", func(doc *parser.Document) error {
+				doc.Format()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if *check && unformatted > 0 {
+		return fmt.Errorf("%d of %d files are not formatted", unformatted, len(files))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"sort"
+	"strings"
+)
+
+var getCommand = &command{
+	name:    "get",
+	usage:   "[-verbose] HOSTNAME",
+	summary: "print the ips of a host name",
+	run:     runGet,
+}
+
+func runGet(env *environment, args []string) error {
+	flags := newFlagSet("get", env)
+	verbose := flags.Bool("verbose", false, "print where each mapping was defined")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errorUsage
+	}
+	hostName := strings.ToLower(flags.Arg(0))
+
+	entrySet, err := hostsfile.Read(env.file)
+	if err != nil {
+		return err
+	}
+
+	ips, ok := entrySet.LookupHost(hostName)
+	if !ok {
+		return fmt.Errorf("%s: %v", hostName, parser.HostNameNotFoundError)
+	}
+	if !*verbose {
+		for _, ip := range ips {
+			fmt.Fprintln(env.stdout, ip)
+		}
+		return nil
+	}
+
+	entries := make([]hosts.Entry, 0)
+	for _, entry := range entrySet.AllEntries() {
+		if entry.Contains(hostName) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return hosts.CompareIPs(entries[i].Ip(), entries[j].Ip()) < 0
+	})
+	for _, entry := range entries {
+		if origin, ok := entry.Origin(hostName); ok {
+			fmt.Fprintf(env.stdout, "%s  %v\n", entry.IpString(), origin)
+		} else {
+			fmt.Fprintln(env.stdout, entry.IpString())
+		}
+	}
+	return nil
+}
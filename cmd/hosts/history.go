@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var historyCommand = &command{
+	name:    "history",
+	usage:   "[-n COUNT] [-diff] [-all]",
+	summary: "show the audit log of the hosts file",
+	run:     runHistory,
+}
+
+func runHistory(env *environment, args []string) error {
+	flags := newFlagSet("history", env)
+	count := flags.Int("n", 0, "show only the last COUNT changes")
+	showDiff := flags.Bool("diff", false, "show the changed lines")
+	all := flags.Bool("all", false, "show the changes of all files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *count < 0 {
+		return errorUsage
+	}
+	if env.audit == "" {
+		return errors.New("auditing is disabled")
+	}
+
+	path, err := filepath.Abs(env.file)
+	if err != nil {
+		return err
+	}
+	audit := &hostsfile.AuditLog{Path: env.audit}
+	records, err := audit.Records()
+	if err != nil {
+		return err
+	}
+
+	selected := make([]hostsfile.AuditRecord, 0)
+	for _, record := range records {
+		if *all || record.Path == path {
+			selected = append(selected, record)
+		}
+	}
+	if *count > 0 && len(selected) > *count {
+		selected = selected[len(selected)-*count:]
+	}
+
+	for _, record := range selected {
+		printRecord(env, record, *all, *showDiff)
+	}
+	return nil
+}
+
+func printRecord(env *environment, record hostsfile.AuditRecord, withPath bool, withChanges bool) {
+	added, removed := 0, 0
+	for _, change := range record.Changes {
+		if change.Op == "+" {
+			added++
+		} else {
+			removed++
+		}
+	}
+
+	user := record.User
+	if record.SudoUser != "" {
+		user = fmt.Sprintf("%s (sudo %s)", user, record.SudoUser)
+	}
+	fields := []string{record.Time.Format(time.RFC3339), user, strings.Join(record.CommandLine, " ")}
+	if withPath {
+		fields = append(fields, record.Path)
+	}
+	fields = append(fields, fmt.Sprintf("+%d -%d", added, removed))
+	fmt.Fprintln(env.stdout, strings.Join(fields, "  "))
+
+	if withChanges {
+		for _, change := range record.Changes {
+			fmt.Fprintf(env.stdout, "    %s%s\n", change.Op, change.Text)
+		}
+	}
+}
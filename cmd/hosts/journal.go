@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+)
+
+var undoCommand = &command{
+	name:    "undo",
+	usage:   "",
+	summary: "revert the last operation on the hosts file",
+	run: func(env *environment, args []string) error {
+		return runJournalOperation("undo", env, args, (*hostsfile.Journal).Undo)
+	},
+}
+
+var redoCommand = &command{
+	name:    "redo",
+	usage:   "",
+	summary: "apply the last reverted operation again",
+	run: func(env *environment, args []string) error {
+		return runJournalOperation("redo", env, args, (*hostsfile.Journal).Redo)
+	},
+}
+
+func journal(env *environment) *hostsfile.Journal {
+	return &hostsfile.Journal{Path: env.journal, Clock: env.clock}
+}
+
+// editDocument applies the edit to the hosts file, the change is recorded in the journal unless it is disabled.
+func editDocument(env *environment, description string, edit func(doc *parser.Document) error) error {
+	return editFile(env, env.file, description, edit)
+}
+
+// editFile applies the edit to the document at path like editDocument.
+func editFile(env *environment, path string, description string, edit func(doc *parser.Document) error) error {
+	if env.journal != "" {
+		return journal(env).Edit(path, description, edit)
+	}
+
+	doc, err := hostsfile.ReadDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+	return hostsfile.WriteDocument(doc, path)
+}
+
+func runJournalOperation(name string, env *environment, args []string,
+	operation func(journal *hostsfile.Journal, path string) (hostsfile.Operation, error)) error {
+
+	flags := newFlagSet(name, env)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errorUsage
+	}
+	if env.journal == "" {
+		return errors.New("the journal is disabled")
+	}
+
+	applied, err := operation(journal(env), env.file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stdout, "%s: %s\n", name, applied.Description)
+	return nil
+}
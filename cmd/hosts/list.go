@@ -0,0 +1,69 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+)
+
+var listCommand = &command{
+	name:    "list",
+	usage:   "[-all] [-tag TAG]",
+	summary: "list the entries",
+	run:     runList,
+}
+
+func runList(env *environment, args []string) error {
+	flags := newFlagSet("list", env)
+	all := flags.Bool("all", false, "list disabled entries too")
+	tag := flags.String("tag", "", "list only entries with the given tag")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errorUsage
+	}
+
+	doc, err := hostsfile.ReadDocument(env.file)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range doc.Lines() {
+		entry := line.Entry()
+		if entry == nil || (line.Disabled() && !*all) || (*tag != "" && !entry.HasTag(*tag)) {
+			continue
+		}
+		text, err := parser.WriteToLine(entry)
+		if err != nil {
+			return err
+		}
+		if line.Disabled() {
+			text = "# " + text
+		}
+		fmt.Fprintln(env.stdout, text)
+	}
+	return nil
+}
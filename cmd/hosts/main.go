@@ -0,0 +1,199 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+/*
+Command hosts reads and modifies an /etc/hosts file.
+
+  hosts [-file PATH] [-audit PATH] [-backup DIR] [-journal PATH] COMMAND [FLAGS] [ARGUMENTS]
+
+Run 'hosts help' for the list of commands.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	defaultFile      = "/etc/hosts"
+	defaultAuditLog  = "/var/log/hosts/audit.log"
+	defaultBackupDir = "/var/backups/hosts"
+	defaultJournal   = "/var/lib/hosts/journal.json"
+	defaultDatabase  = "/var/lib/hosts/hosts.db"
+	// backups exceeding the count or the age are removed
+	defaultBackupCount  = 50
+	defaultBackupMaxAge = 90 * 24 * time.Hour
+)
+
+var errorUsage = errors.New("usage")
+
+// A command is a sub-command of the hosts tool.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(env *environment, args []string) error
+}
+
+// environment holds everything a command interacts with, so commands can be tested.
+type environment struct {
+	file    string
+	audit   string
+	backup  string
+	journal string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	clock   hostsfile.Clock
+}
+
+var commands = []*command{
+	listCommand,
+	getCommand,
+	addCommand,
+	removeCommand,
+	enableCommand,
+	disableCommand,
+	profileCommand,
+	compileCommand,
+	reapCommand,
+	historyCommand,
+	backupCommand,
+	restoreCommand,
+	undoCommand,
+	redoCommand,
+	mergeDriverCommand,
+	fmtCommand,
+	convertCommand,
+	renderCommand,
+	compileDBCommand,
+	lookupCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &environment{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		clock:  hostsfile.SystemClock,
+	}))
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, env *environment) int {
+	flags := flag.NewFlagSet("hosts", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.StringVar(&env.file, "file", defaultFile, "path of the hosts file")
+	flags.StringVar(&env.audit, "audit", defaultAuditLog, "path of the audit log, empty to disable auditing (only used by default for "+defaultFile+")")
+	flags.StringVar(&env.backup, "backup", defaultBackupDir, "directory of the backups, empty to disable backups (only used by default for "+defaultFile+")")
+	flags.StringVar(&env.journal, "journal", defaultJournal, "path of the undo journal, empty to disable undo (only used by default for "+defaultFile+")")
+	flags.Usage = func() { printUsage(env.stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	disableSystemDefaults(flags, env)
+
+	if flags.NArg() == 0 || flags.Arg(0) == "help" {
+		printUsage(env.stderr, flags)
+		return 2
+	}
+
+	cmd := findCommand(flags.Arg(0))
+	if cmd == nil {
+		fmt.Fprintf(env.stderr, "hosts: unknown command '%s'\n", flags.Arg(0))
+		printUsage(env.stderr, flags)
+		return 2
+	}
+
+	if env.backup != "" {
+		unregister := hostsfile.RegisterWriteHook(backupStore(env))
+		defer unregister()
+	}
+	if env.audit != "" {
+		unregister := hostsfile.RegisterWriteHook(&hostsfile.AuditLog{Path: env.audit, Clock: env.clock})
+		defer unregister()
+	}
+
+	err := cmd.run(env, flags.Args()[1:])
+	if err == errorUsage || err == flag.ErrHelp {
+		fmt.Fprintf(env.stderr, "usage: hosts %s %s\n", cmd.name, cmd.usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "hosts %s: %v\n", cmd.name, err)
+		return 1
+	}
+	return 0
+}
+
+// disableSystemDefaults disables the audit log, the backups and the journal, unless they were set explicitly,
+// if the file is not the system hosts file: their default paths belong to the system hosts file and are usually
+// not writable for other users.
+func disableSystemDefaults(flags *flag.FlagSet, env *environment) {
+	if env.file == defaultFile {
+		return
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["audit"] {
+		env.audit = ""
+	}
+	if !set["backup"] {
+		env.backup = ""
+	}
+	if !set["journal"] {
+		env.journal = ""
+	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func printUsage(writer io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(writer, "usage: hosts [-file PATH] [-audit PATH] [-backup DIR] [-journal PATH] COMMAND [FLAGS] [ARGUMENTS]")
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(writer, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(writer)
+	flags.PrintDefaults()
+}
+
+// newFlagSet returns the flag set of the given command, its errors are reported to stderr.
+func newFlagSet(cmd string, env *environment) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	return flags
+}
@@ -0,0 +1,566 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts/hostsfile"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// testEnvironment creates a hosts file with the given content in a temporary directory.
+type testEnvironment struct {
+	dir    string
+	file   string
+	stdin  *bytes.Buffer
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+	t      *testing.T
+}
+
+func newTestEnvironment(content string, t *testing.T) *testEnvironment {
+	dir, err := ioutil.TempDir("", "hosts-cmd")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	file := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return &testEnvironment{
+		dir:    dir,
+		file:   file,
+		stdin:  &bytes.Buffer{},
+		stdout: &bytes.Buffer{},
+		stderr: &bytes.Buffer{},
+		t:      t,
+	}
+}
+
+func (e *testEnvironment) cleanup() {
+	os.RemoveAll(e.dir)
+}
+
+func (e *testEnvironment) run(args ...string) int {
+	e.stdout.Reset()
+	e.stderr.Reset()
+	return run(append([]string{"-file", e.file, "-audit", filepath.Join(e.dir, "audit.log"),
+		"-backup", filepath.Join(e.dir, "backups"), "-journal", filepath.Join(e.dir, "journal.json")}, args...), &environment{
+		stdin:  e.stdin,
+		stdout: e.stdout,
+		stderr: e.stderr,
+		clock:  fixedClock{now: testTime},
+	})
+}
+
+func (e *testEnvironment) mustRun(args ...string) {
+	if code := e.run(args...); code != 0 {
+		e.t.Fatalf("hosts %s: unexpected exit code %d, stderr: %s", strings.Join(args, " "), code, e.stderr)
+	}
+}
+
+func (e *testEnvironment) assertContent(expected string) {
+	content, err := ioutil.ReadFile(e.file)
+	if err != nil {
+		e.t.Fatalf("unexpected error %v", err)
+	}
+	if string(content) != expected {
+		e.t.Errorf("expected '%s' actual '%s'", expected, string(content))
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	env := newTestEnvironment("", t)
+	defer env.cleanup()
+
+	tests := [][]string{
+		{},
+		{"help"},
+		{"unknown"},
+		{"add", "10.1.2.3"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			if code := env.run(args...); code != 2 {
+				t.Errorf("expected exit code 2, actual %d", code)
+			}
+		})
+	}
+}
+
+func TestRun_SystemDefaults(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	// the system audit log, backups and journal only apply to the system hosts file, a write to another file
+	// must not need access to them
+	journal := filepath.Join(env.dir, "journal.json")
+	actual := &environment{stdin: env.stdin, stdout: env.stdout, stderr: env.stderr, clock: fixedClock{now: testTime}}
+	if code := run([]string{"-file", env.file, "-journal", journal, "add", "10.1.2.3", "api.prod"}, actual); code != 0 {
+		t.Fatalf("unexpected exit code %d, stderr: %s", code, env.stderr)
+	}
+	if actual.audit != "" || actual.backup != "" || actual.journal != journal {
+		t.Errorf("unexpected audit '%s', backup '%s' and journal '%s'", actual.audit, actual.backup, actual.journal)
+	}
+	env.assertContent("127.0.0.1 localhost\n10.1.2.3  api.prod\n")
+	if _, err := os.Stat(journal); err != nil {
+		t.Errorf("expected the explicit journal to be written, actual %v", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	env.mustRun("add", "-ttl", "2h", "10.1.2.3", "api.prod")
+	env.mustRun("add", "--comment", "owner=payments", "10.1.2.4", "web.prod", "www.prod")
+
+	env.assertContent(`127.0.0.1 localhost
+10.1.2.3  api.prod  # expires=2020-05-01T14:00:00Z
+10.1.2.4  web.prod  www.prod  # owner=payments
+`)
+
+	if code := env.run("add", "10.1.2", "api.prod"); code != 1 {
+		t.Errorf("expected exit code 1 for an invalid ip, actual %d", code)
+	}
+}
+
+func TestReapOnce(t *testing.T) {
+	env := newTestEnvironment(`127.0.0.1 localhost
+10.1.2.3 api.prod # expires=2020-05-01T12:00:00Z
+10.1.2.4 web.prod # expires=2020-05-01T14:00:00Z
+`, t)
+	defer env.cleanup()
+
+	env.mustRun("reap", "-once")
+
+	env.assertContent("127.0.0.1 localhost\n10.1.2.4 web.prod # expires=2020-05-01T14:00:00Z\n")
+	if !strings.Contains(env.stdout.String(), "api.prod") {
+		t.Errorf("expected expired entry in output '%s'", env.stdout)
+	}
+}
+
+const taggedContent = `127.0.0.1 localhost
+10.0.0.1 api.staging # tags=billing,staging
+# 10.0.0.2 web.staging # tags=staging
+10.0.0.3 api.dev # tags=dev
+`
+
+func TestList(t *testing.T) {
+	env := newTestEnvironment(taggedContent, t)
+	defer env.cleanup()
+
+	env.mustRun("list", "-tag", "staging")
+	expected := "10.0.0.1  api.staging  # tags=billing,staging\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	env.mustRun("list", "-all", "-tag", "staging")
+	expected += "# 10.0.0.2  web.staging  # tags=staging\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+}
+
+func TestDisableEnableTag(t *testing.T) {
+	env := newTestEnvironment(taggedContent, t)
+	defer env.cleanup()
+
+	env.mustRun("disable", "-tag", "staging")
+	env.mustRun("enable", "api.dev")
+	env.assertContent(`127.0.0.1 localhost
+# 10.0.0.1 api.staging # tags=billing,staging
+# 10.0.0.2 web.staging # tags=staging
+10.0.0.3 api.dev # tags=dev
+`)
+
+	env.mustRun("remove", "-tag", "staging")
+	env.assertContent("127.0.0.1 localhost\n10.0.0.3 api.dev # tags=dev\n")
+
+	if code := env.run("enable", "-tag", "staging", "api.dev"); code != 2 {
+		t.Errorf("expected exit code 2 for -tag together with host names, actual %d", code)
+	}
+	if code := env.run("disable", "unknown.local"); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown host name, actual %d", code)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	dir := filepath.Join(env.dir, "profiles")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for name, content := range map[string]string{"local": "127.0.0.1 api.local\n", "staging": "10.0.0.1 api.local\n"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".hosts"), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	env.mustRun("profile", "-dir", dir, "activate", "local")
+	env.assertContent("127.0.0.1 localhost\n# BEGIN hosts profile local\n127.0.0.1 api.local\n# END hosts profile local\n")
+
+	env.mustRun("profile", "-dir", dir, "list")
+	if env.stdout.String() != "* local\n  staging\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+
+	if code := env.run("profile", "-dir", dir, "activate", "staging"); code != 1 {
+		t.Errorf("expected exit code 1 for a conflicting profile, actual %d", code)
+	}
+	if !strings.Contains(env.stderr.String(), "api.local") {
+		t.Errorf("expected conflicting host name in '%s'", env.stderr)
+	}
+
+	env.mustRun("profile", "-dir", dir, "deactivate", "local")
+	env.mustRun("profile", "-dir", dir, "activate", "staging")
+	env.assertContent("127.0.0.1 localhost\n# BEGIN hosts profile staging\n10.0.0.1 api.local\n# END hosts profile staging\n")
+
+	env.mustRun("profile", "-dir", dir, "show", "staging")
+	if env.stdout.String() != "10.0.0.1 api.local\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	env := newTestEnvironment("", t)
+	defer env.cleanup()
+
+	base := filepath.Join(env.dir, "hosts.base")
+	dir := filepath.Join(env.dir, "hosts.d")
+	if err := ioutil.WriteFile(base, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "10-vpn.hosts"), []byte("10.8.0.1 vpn.internal\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	env.mustRun("compile", "-base", base, "-dir", dir, "-v")
+	if env.stdout.String() != env.file+" updated\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	env.assertContent("127.0.0.1 localhost\n# --- " + filepath.Join(dir, "10-vpn.hosts") + " ---\n10.8.0.1 vpn.internal\n")
+
+	env.mustRun("compile", "-base", base, "-dir", dir, "-v")
+	if env.stdout.String() != env.file+" unchanged\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+}
+
+func TestGet(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n::1 localhost\n10.0.0.1 api.local\n", t)
+	defer env.cleanup()
+
+	env.mustRun("get", "LOCALHOST")
+	if env.stdout.String() != "127.0.0.1\n::1\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+
+	env.mustRun("get", "-verbose", "localhost")
+	expected := "127.0.0.1  hosts (" + env.file + ":1)\n::1  hosts (" + env.file + ":2)\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	// the mappings are ordered by ip, not by their text
+	env.mustRun("add", "10.0.0.10", "api.local")
+	env.mustRun("add", "10.0.0.9", "api.local")
+	env.mustRun("get", "-verbose", "api.local")
+	expected = "10.0.0.1  hosts (" + env.file + ":3)\n10.0.0.9  hosts (" + env.file + ":5)\n" +
+		"10.0.0.10  hosts (" + env.file + ":4)\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	if code := env.run("get", "unknown.local"); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown host name, actual %d", code)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	env.mustRun("add", "10.1.2.3", "api.prod")
+	env.mustRun("remove", "api.prod")
+
+	env.mustRun("history", "-diff")
+	lines := strings.Split(strings.TrimSpace(env.stdout.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 2 records with 1 change each, actual '%s'", env.stdout)
+	}
+	if !strings.HasPrefix(lines[0], "2020-05-01T12:00:00Z  ") || !strings.HasSuffix(lines[0], "  +1 -0") {
+		t.Errorf("unexpected record '%s'", lines[0])
+	}
+	if lines[1] != "    +10.1.2.3  api.prod" || lines[3] != "    -10.1.2.3  api.prod" {
+		t.Errorf("unexpected changes '%s'", env.stdout)
+	}
+
+	env.mustRun("history", "-n", "1")
+	if !strings.HasSuffix(strings.TrimSpace(env.stdout.String()), "  +0 -1") {
+		t.Errorf("expected only the last record, actual '%s'", env.stdout)
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	env.mustRun("add", "10.1.2.3", "api.prod")
+	env.mustRun("remove", "api.prod")
+
+	env.mustRun("backup", "list")
+	lines := strings.Split(strings.TrimSpace(env.stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "  2020-05-01T12:00:00Z  39 bytes") {
+		t.Fatalf("unexpected backups '%s'", env.stdout)
+	}
+	id := strings.Fields(lines[1])[0]
+
+	env.stdin.WriteString("n\n")
+	if code := env.run("restore", id); code != 1 {
+		t.Errorf("expected exit code 1 if the restore is not confirmed, actual %d", code)
+	}
+	env.assertContent("127.0.0.1 localhost\n")
+
+	env.stdin.WriteString("y\n")
+	env.mustRun("restore", id)
+	expected := "--- " + env.file + "\n+++ " + id + "\n@@ -1 +1,2 @@\n 127.0.0.1 localhost\n+10.1.2.3  api.prod\n" +
+		"restore " + env.file + " from backup " + id + "? [y/N] "
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+	env.assertContent("127.0.0.1 localhost\n10.1.2.3  api.prod\n")
+
+	if code := env.run("restore", "unknown"); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown backup, actual %d", code)
+	}
+}
+
+func TestBackupRestore_OtherFile(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	fragment := filepath.Join(env.dir, "fragment.hosts")
+	if err := ioutil.WriteFile(fragment, []byte("10.1.2.3   api.prod\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	env.mustRun("fmt", "-w", fragment)
+
+	// the backup of the fragment is neither listed nor restored for the hosts file
+	env.mustRun("backup", "list")
+	if env.stdout.String() != "" {
+		t.Errorf("expected no backups of the hosts file, actual '%s'", env.stdout)
+	}
+	store := &hostsfile.BackupStore{Dir: filepath.Join(env.dir, "backups")}
+	backups, err := store.List(fragment)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected the backup of the fragment, actual %v, error %v", backups, err)
+	}
+	if code := env.run("restore", "-y", backups[0].ID); code != 1 {
+		t.Errorf("expected exit code 1 for a backup of another file, actual %d", code)
+	}
+	env.assertContent("127.0.0.1 localhost\n")
+}
+
+func TestUndoRedo(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	env.mustRun("add", "10.1.2.3", "api.prod")
+	env.mustRun("disable", "api.prod")
+
+	env.mustRun("undo")
+	if env.stdout.String() != "undo: disable api.prod\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	env.assertContent("127.0.0.1 localhost\n10.1.2.3  api.prod\n")
+
+	env.mustRun("undo")
+	env.assertContent("127.0.0.1 localhost\n")
+	if code := env.run("undo"); code != 1 {
+		t.Errorf("expected exit code 1 if there is nothing to undo, actual %d", code)
+	}
+
+	env.mustRun("redo")
+	if env.stdout.String() != "redo: add 10.1.2.3 api.prod\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	env.assertContent("127.0.0.1 localhost\n10.1.2.3  api.prod\n")
+
+	// every write of the hosts file is recorded, not only those of add, enable, disable and profile
+	env.mustRun("fmt", "-w")
+	env.assertContent("127.0.0.1  localhost\n10.1.2.3  api.prod\n")
+	env.mustRun("undo")
+	if env.stdout.String() != "undo: fmt -w\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	env.assertContent("127.0.0.1 localhost\n10.1.2.3  api.prod\n")
+}
+
+func TestMergeDriver(t *testing.T) {
+	env := newTestEnvironment("", t)
+	defer env.cleanup()
+
+	write := func(name, content string) string {
+		path := filepath.Join(env.dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return path
+	}
+	base := write("base", "127.0.0.1 localhost\n10.0.0.1 a b\n")
+	ours := write("ours", "127.0.0.1 localhost\n10.0.0.1 a b c\n")
+	theirs := write("theirs", "127.0.0.1 localhost\n10.0.0.1 a\n")
+
+	env.mustRun("merge-driver", base, ours, theirs)
+	env.file = ours
+	env.assertContent("127.0.0.1 localhost\n10.0.0.1  a  c\n")
+
+	write("ours", "127.0.0.1 localhost\n10.0.0.3 a b\n")
+	write("theirs", "127.0.0.1 localhost\n10.0.0.2 a b\n")
+	if code := env.run("merge-driver", "-marker-size", "3", "-path", "hosts", base, ours, theirs); code != 1 {
+		t.Errorf("expected exit code 1 for a conflict, actual %d", code)
+	}
+	env.assertContent("127.0.0.1 localhost\n<<< ours:hosts\n10.0.0.3 a b\n||| base:hosts\n10.0.0.1 a b\n" +
+		"===\n10.0.0.2 a b\n>>> theirs:hosts\n")
+	if !strings.Contains(env.stderr.String(), "conflict: a: base [10.0.0.1], ours [10.0.0.3], theirs [10.0.0.2]") {
+		t.Errorf("expected the conflicts on stderr, actual '%s'", env.stderr)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1\tlocalhost\n\n\n10.0.0.1 web.local   # owner=web\n", t)
+	defer env.cleanup()
+	formatted := "127.0.0.1  localhost\n\n10.0.0.1  web.local  # owner=web\n"
+
+	env.mustRun("fmt")
+	if env.stdout.String() != formatted {
+		t.Errorf("expected '%s' actual '%s'", formatted, env.stdout)
+	}
+
+	env.mustRun("fmt", "-l", "-d")
+	expected := env.file + "\n--- " + env.file + "\n+++ " + env.file + " (formatted)\n" +
+		"@@ -1,4 +1,3 @@\n-127.0.0.1\tlocalhost\n-\n+127.0.0.1  localhost\n \n-10.0.0.1 web.local   # owner=web\n+10.0.0.1  web.local  # owner=web\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	if code := env.run("fmt", "--check"); code != 1 {
+		t.Errorf("expected exit code 1 for an unformatted file, actual %d", code)
+	}
+	env.assertContent("127.0.0.1\tlocalhost\n\n\n10.0.0.1 web.local   # owner=web\n")
+
+	env.mustRun("fmt", "-w")
+	env.assertContent(formatted)
+	env.mustRun("fmt", "-check", "-l")
+	if env.stdout.String() != "" {
+		t.Errorf("expected no unformatted files, actual '%s'", env.stdout)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	env := newTestEnvironment("10.0.0.1 api.local web.local\n127.0.0.1 localhost\n", t)
+	defer env.cleanup()
+
+	env.mustRun("convert", "--from", "auto", "--to", "dnsmasq")
+	expected := "host-record=localhost,127.0.0.1\nhost-record=api.local,web.local,10.0.0.1\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	env.stdin.WriteString(expected)
+	env.mustRun("convert", "-")
+	expected = "127.0.0.1  localhost\n10.0.0.1  api.local  web.local\n"
+	if env.stdout.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, env.stdout)
+	}
+
+	if code := env.run("convert", "-to", "unknown"); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown format, actual %d", code)
+	}
+}
+
+func TestRender(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n10.0.0.1 api.local\n", t)
+	defer env.cleanup()
+
+	template := filepath.Join(env.dir, "ssh_config.tmpl")
+	text := "{{range .Entries}}{{if not (isLoopback .Ip)}}Host {{join \" \" .HostNames}}\n  HostName {{.Ip}}\n{{end}}{{end}}"
+	if err := ioutil.WriteFile(template, []byte(text), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	env.mustRun("render", "-template", template)
+	if env.stdout.String() != "Host api.local\n  HostName 10.0.0.1\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	if code := env.run("render"); code != 2 {
+		t.Errorf("expected exit code 2 without template, actual %d", code)
+	}
+}
+
+func TestCompileDBAndLookup(t *testing.T) {
+	env := newTestEnvironment("127.0.0.1 localhost\n::1 localhost\n10.0.0.1 api.local web.local\n", t)
+	defer env.cleanup()
+
+	db := filepath.Join(env.dir, "hosts.db")
+	env.mustRun("compile-db", "-o", db)
+
+	env.mustRun("lookup", "-db", db, "LocalHost")
+	if env.stdout.String() != "127.0.0.1\n::1\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	env.stdout.Reset()
+	env.mustRun("lookup", "-db", db, "10.0.0.1")
+	if env.stdout.String() != "api.local web.local\n" {
+		t.Errorf("unexpected output '%s'", env.stdout)
+	}
+	if code := env.run("lookup", "-db", db, "unknown.local"); code != 1 {
+		t.Errorf("expected exit code 1 for an unknown host name, actual %d", code)
+	}
+}
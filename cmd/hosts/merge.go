@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts/merge"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"os"
+)
+
+var mergeDriverCommand = &command{
+	name:    "merge-driver",
+	usage:   "[-marker-size SIZE] [-path PATH] BASE OURS THEIRS",
+	summary: "merge hosts files as git merge driver, the result is written to OURS",
+	run:     runMergeDriver,
+}
+
+func runMergeDriver(env *environment, args []string) error {
+	flags := newFlagSet("merge-driver", env)
+	markerSize := flags.Int("marker-size", merge.DefaultMarkerSize, "length of the conflict markers (%L)")
+	path := flags.String("path", "", "path of the merged file in the repository (%P)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 3 {
+		return errorUsage
+	}
+
+	docs := make([]*parser.Document, 3)
+	for i, file := range flags.Args() {
+		doc, err := readDocument(file)
+		if err != nil {
+			return err
+		}
+		docs[i] = doc
+	}
+
+	result := merge.Merge(docs[0], docs[1], docs[2])
+	markers := merge.Markers{Size: *markerSize, Base: "base", Ours: "ours", Theirs: "theirs"}
+	if *path != "" {
+		markers.Base += ":" + *path
+		markers.Ours += ":" + *path
+		markers.Theirs += ":" + *path
+	}
+	if err := ioutil.WriteFile(flags.Arg(1), result.Bytes(markers), 0644); err != nil {
+		return err
+	}
+
+	for _, conflict := range result.Conflicts {
+		fmt.Fprintf(env.stderr, "conflict: %v\n", conflict)
+	}
+	if !result.Clean() {
+		return errors.New("the merge has conflicts")
+	}
+	return nil
+}
+
+func readDocument(path string) (*parser.Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	doc, err := parser.ReadDocument(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"github.com/bitofcode/hosts/profile"
+	"strings"
+)
+
+const defaultProfileDir = "/etc/hosts.profiles"
+
+var profileCommand = &command{
+	name:    "profile",
+	usage:   "[-dir DIR] list | show NAME | activate NAME... | deactivate NAME...",
+	summary: "switch between named sets of overrides",
+	run:     runProfile,
+}
+
+func runProfile(env *environment, args []string) error {
+	flags := newFlagSet("profile", env)
+	dir := flags.String("dir", defaultProfileDir, "directory of the profiles (NAME.hosts)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errorUsage
+	}
+
+	store := profile.NewStore(*dir)
+	names := flags.Args()[1:]
+	switch flags.Arg(0) {
+	case "list":
+		if len(names) != 0 {
+			return errorUsage
+		}
+		return listProfiles(env, store)
+	case "show":
+		if len(names) != 1 {
+			return errorUsage
+		}
+		doc, err := store.Load(names[0])
+		if err != nil {
+			return err
+		}
+		_, err = doc.WriteTo(env.stdout)
+		return err
+	case "activate":
+		if len(names) == 0 {
+			return errorUsage
+		}
+		return updateProfiles(env, "activate", names, store.Activate)
+	case "deactivate":
+		if len(names) == 0 {
+			return errorUsage
+		}
+		return updateProfiles(env, "deactivate", names, profile.Deactivate)
+	}
+	return errorUsage
+}
+
+func listProfiles(env *environment, store *profile.Store) error {
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+	doc, err := hostsfile.ReadDocument(env.file)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		marker := " "
+		if profile.IsActive(doc, name) {
+			marker = "*"
+		}
+		fmt.Fprintf(env.stdout, "%s %s\n", marker, name)
+	}
+	return nil
+}
+
+func updateProfiles(env *environment, operation string, names []string,
+	update func(doc *parser.Document, name string) error) error {
+
+	description := fmt.Sprintf("profile %s %s", operation, strings.Join(names, " "))
+	return editDocument(env, description, func(doc *parser.Document) error {
+		for _, name := range names {
+			if err := update(doc, name); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var reapCommand = &command{
+	name:    "reap",
+	usage:   "[-once] [-interval DURATION]",
+	summary: "remove expired entries, keeps running until interrupted unless -once is given",
+	run:     runReap,
+}
+
+func runReap(env *environment, args []string) error {
+	flags := newFlagSet("reap", env)
+	once := flags.Bool("once", false, "remove expired entries once and exit")
+	interval := flags.Duration("interval", hostsfile.DefaultReapInterval, "maximum time between two checks of the file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *interval <= 0 {
+		return errorUsage
+	}
+
+	reaper := &hostsfile.Reaper{
+		Path:     env.file,
+		Clock:    env.clock,
+		Interval: *interval,
+		Notify:   func(expired []hosts.Entry) { printExpired(env, expired) },
+	}
+
+	if *once {
+		expired, err := reaper.Reap()
+		printExpired(env, expired)
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	return reaper.Run(stop)
+}
+
+func printExpired(env *environment, expired []hosts.Entry) {
+	for _, entry := range expired {
+		line, err := parser.WriteToLine(entry)
+		if err == nil {
+			fmt.Fprintf(env.stdout, "expired: %s\n", line)
+		}
+	}
+}
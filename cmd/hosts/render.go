@@ -0,0 +1,57 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+)
+
+var renderCommand = &command{
+	name:    "render",
+	usage:   "-template FILE",
+	summary: "render the entries with a Go text/template",
+	run:     runRender,
+}
+
+func runRender(env *environment, args []string) error {
+	flags := newFlagSet("render", env)
+	templateFile := flags.String("template", "", "file of the text/template, executed with .Entries")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *templateFile == "" {
+		return errorUsage
+	}
+
+	text, err := ioutil.ReadFile(*templateFile)
+	if err != nil {
+		return err
+	}
+	entrySet, err := hostsfile.Read(env.file)
+	if err != nil {
+		return err
+	}
+	return parser.WriteTemplate(entrySet, env.stdout, string(text))
+}
@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts/parser"
+	"strings"
+)
+
+var enableCommand = &command{
+	name:    "enable",
+	usage:   "-tag TAG | HOSTNAME...",
+	summary: "enable disabled entries",
+	run: func(env *environment, args []string) error {
+		return runDocumentOperation("enable", env, args, (*parser.Document).Enable, (*parser.Document).EnableTag)
+	},
+}
+
+var disableCommand = &command{
+	name:    "disable",
+	usage:   "-tag TAG | HOSTNAME...",
+	summary: "disable entries by commenting them out",
+	run: func(env *environment, args []string) error {
+		return runDocumentOperation("disable", env, args, (*parser.Document).Disable, (*parser.Document).DisableTag)
+	},
+}
+
+var removeCommand = &command{
+	name:    "remove",
+	usage:   "-tag TAG | HOSTNAME...",
+	summary: "remove host names or all entries with a tag",
+	run: func(env *environment, args []string) error {
+		return runDocumentOperation("remove", env, args, (*parser.Document).Remove, (*parser.Document).RemoveTag)
+	},
+}
+
+// runDocumentOperation applies the operation to every host name argument or to the tag given by -tag.
+func runDocumentOperation(name string, env *environment, args []string,
+	byHostName func(doc *parser.Document, hostName string) error,
+	byTag func(doc *parser.Document, tag string) error) error {
+
+	flags := newFlagSet(name, env)
+	tag := flags.String("tag", "", "apply to all entries with the given tag")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*tag == "") == (flags.NArg() == 0) {
+		return errorUsage
+	}
+
+	return editDocument(env, name+" "+strings.Join(args, " "), func(doc *parser.Document) error {
+		if *tag != "" {
+			if err := byTag(doc, *tag); err != nil {
+				return fmt.Errorf("%s: %v", *tag, err)
+			}
+		}
+		for _, hostName := range flags.Args() {
+			if err := byHostName(doc, hostName); err != nil {
+				return fmt.Errorf("%s: %v", hostName, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,208 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+/*
+Package codec provides a registry of named formats to decode and encode a hosts.EntrySet, e.g. hosts, dnsmasq or json.
+
+Additional formats are registered with Register, usually in the init function of the package implementing them:
+
+  func init() {
+    codec.Register(codec.Format{Name: "unbound", Decoder: decoder, Encoder: encoder, Sniff: sniff})
+  }
+*/
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Auto is the name to detect the format of the input by its content.
+const Auto = "auto"
+
+// sniffLength is the number of bytes passed to the sniff functions.
+const sniffLength = 4096
+
+var (
+	ErrorUnknownFormat = errors.New("unknown format")
+	ErrorNotDetected   = errors.New("format not detected")
+	ErrorNotSupported  = errors.New("operation not supported by the format")
+)
+
+// A Decoder reads an EntrySet in a specific format.
+type Decoder interface {
+	Decode(reader io.Reader) (hosts.EntrySet, error)
+}
+
+// An Encoder writes an EntrySet in a specific format.
+type Encoder interface {
+	Encode(entrySet hosts.EntrySet, writer io.Writer) error
+}
+
+// DecoderFunc is an ordinary function used as Decoder.
+type DecoderFunc func(reader io.Reader) (hosts.EntrySet, error)
+
+func (f DecoderFunc) Decode(reader io.Reader) (hosts.EntrySet, error) {
+	return f(reader)
+}
+
+// EncoderFunc is an ordinary function used as Encoder.
+type EncoderFunc func(entrySet hosts.EntrySet, writer io.Writer) error
+
+func (f EncoderFunc) Encode(entrySet hosts.EntrySet, writer io.Writer) error {
+	return f(entrySet, writer)
+}
+
+// A Format is a named pair of Decoder and Encoder, either may be nil if the format is read-only or write-only.
+type Format struct {
+	Name    string
+	Decoder Decoder
+	Encoder Encoder
+	// Sniff reports whether the beginning of an input is in this format, nil if the format cannot be detected.
+	Sniff func(head []byte) bool
+}
+
+var (
+	formatsMutex sync.RWMutex
+	formats      []Format
+)
+
+// Register makes the format available by its name, the formats are sniffed in the order they were registered.
+// It panics if the name is empty, Auto or already registered.
+func Register(format Format) {
+	formatsMutex.Lock()
+	defer formatsMutex.Unlock()
+
+	if format.Name == "" || format.Name == Auto {
+		panic(fmt.Sprintf("codec: invalid format name '%s'", format.Name))
+	}
+	for _, registered := range formats {
+		if registered.Name == format.Name {
+			panic(fmt.Sprintf("codec: format '%s' is already registered", format.Name))
+		}
+	}
+	formats = append(formats, format)
+}
+
+// Lookup returns the format with the given name.
+func Lookup(name string) (Format, bool) {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+
+	for _, format := range formats {
+		if format.Name == name {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names returns the names of all registered formats in lexical order.
+func Names() []string {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = format.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Detect returns the first registered format which can decode and recognises the beginning of an input.
+func Detect(head []byte) (Format, bool) {
+	formatsMutex.RLock()
+	defer formatsMutex.RUnlock()
+
+	for _, format := range formats {
+		if format.Decoder != nil && format.Sniff != nil && format.Sniff(head) {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// Decode reads an EntrySet in the format with the given name, Auto detects the format from the input.
+func Decode(name string, reader io.Reader) (hosts.EntrySet, error) {
+	if name == Auto {
+		buffered := bufio.NewReaderSize(reader, sniffLength)
+		head, err := buffered.Peek(sniffLength)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		format, ok := Detect(head)
+		if !ok {
+			return nil, ErrorNotDetected
+		}
+		return format.Decoder.Decode(buffered)
+	}
+
+	format, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: %v", name, ErrorUnknownFormat)
+	}
+	if format.Decoder == nil {
+		return nil, fmt.Errorf("%s: %v", name, ErrorNotSupported)
+	}
+	return format.Decoder.Decode(reader)
+}
+
+// Encode writes the EntrySet in the format with the given name.
+func Encode(name string, entrySet hosts.EntrySet, writer io.Writer) error {
+	format, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("%s: %v", name, ErrorUnknownFormat)
+	}
+	if format.Encoder == nil {
+		return fmt.Errorf("%s: %v", name, ErrorNotSupported)
+	}
+	return format.Encoder.Encode(entrySet, writer)
+}
+
+// sortedEntries returns the entries of the EntrySet ordered by hosts.CompareIPs.
+func sortedEntries(entrySet hosts.EntrySet) []hosts.Entry {
+	entries := entrySet.AllEntries()
+	sort.Slice(entries, func(i, j int) bool {
+		return hosts.CompareIPs(entries[i].Ip(), entries[j].Ip()) < 0
+	})
+	return entries
+}
+
+// firstLine returns the first line of the head which is neither blank nor a comment starting with the comment sign.
+func firstLine(head []byte, commentSign string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, commentSign) {
+			return line, true
+		}
+	}
+	return "", false
+}
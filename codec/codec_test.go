@@ -0,0 +1,149 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package codec
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+const (
+	hostsContent   = "# local\n127.0.0.1  localhost\n10.0.0.1  api.local  web.local\n"
+	dnsmasqContent = "# generated\nhost-record=localhost,127.0.0.1\naddress=/api.local/web.local/10.0.0.1\n"
+	jsonContent    = `[
+  {"ip": "127.0.0.1", "host_names": ["localhost"]},
+  {"ip": "10.0.0.1", "host_names": ["api.local", "web.local"]}
+]`
+)
+
+func TestDecode(t *testing.T) {
+	for _, content := range []string{hostsContent, dnsmasqContent, jsonContent} {
+		entrySet, err := Decode(Auto, strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for _, hostName := range []string{"localhost", "api.local", "web.local"} {
+			if _, ok := entrySet.LookupHost(hostName); !ok {
+				t.Errorf("expected %s to be decoded from '%s'", hostName, content)
+			}
+		}
+	}
+
+	if _, err := Decode("unknown", strings.NewReader(hostsContent)); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	if _, err := Decode(Auto, strings.NewReader("server=8.8.8.8\n")); err != ErrorNotDetected {
+		t.Errorf("expected %v, actual %v", ErrorNotDetected, err)
+	}
+}
+
+func TestDecodeDnsmasq_Skipped(t *testing.T) {
+	content := "host-record=,10.0.0.1\n" +
+		"address=/#/0.0.0.0\n" +
+		"address=/#/tracker.example/0.0.0.0\n" +
+		"address=/ads.example.com/\n" +
+		"host-record=api.local,10.0.0.2,3600\n"
+	entrySet, err := Decode(Dnsmasq, strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	entries := entrySet.AllEntries()
+	if len(entries) != 1 || entries[0].IpString() != "10.0.0.2" || strings.Join(entries[0].HostNames(), " ") != "api.local" {
+		t.Errorf("expected only the entry of api.local, actual %v", entries)
+	}
+
+	// every decoded entry can be encoded again
+	if err := Encode(Hosts, entrySet, &bytes.Buffer{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestDecodeDnsmasq_NullAddress(t *testing.T) {
+	entrySet, err := Decode(Dnsmasq, strings.NewReader("address=/ads.example/tracker.example/#\n"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, ip := range []string{"0.0.0.0", "::"} {
+		hostNames, ok := entrySet.EntriesOfIP(net.ParseIP(ip))
+		if !ok || strings.Join(hostNames, " ") != "ads.example tracker.example" {
+			t.Errorf("expected the null address %s for both names, actual %v", ip, hostNames)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	entrySet := hosts.NewEntrySet()
+	entrySet.AddEntry(
+		hosts.NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}),
+		hosts.NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	tests := map[string]string{
+		Hosts:   "127.0.0.1  localhost\n10.0.0.1  api.local  web.local\n",
+		Dnsmasq: "host-record=localhost,127.0.0.1\nhost-record=api.local,web.local,10.0.0.1\n",
+		JSON: "[\n  {\n    \"ip\": \"127.0.0.1\",\n    \"host_names\": [\n      \"localhost\"\n    ]\n  },\n" +
+			"  {\n    \"ip\": \"10.0.0.1\",\n    \"host_names\": [\n      \"api.local\",\n      \"web.local\"\n    ]\n  }\n]\n",
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			if err := Encode(name, entrySet, buffer); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if buffer.String() != expected {
+				t.Errorf("expected '%s' actual '%s'", expected, buffer.String())
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(Format{
+		Name: "names",
+		Encoder: EncoderFunc(func(entrySet hosts.EntrySet, writer io.Writer) error {
+			for _, entry := range sortedEntries(entrySet) {
+				if _, err := io.WriteString(writer, strings.Join(entry.HostNames(), "\n")+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	})
+
+	if strings.Join(Names(), " ") != "dnsmasq hosts json names" {
+		t.Errorf("unexpected formats %v", Names())
+	}
+	if _, err := Decode("names", strings.NewReader("")); err == nil {
+		t.Errorf("expected an error for a format without decoder")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a duplicate format")
+		}
+	}()
+	Register(Format{Name: Hosts})
+}
@@ -0,0 +1,205 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"io"
+	"net"
+	"strings"
+)
+
+// The names of the built-in formats.
+const (
+	Hosts   = "hosts"
+	Dnsmasq = "dnsmasq"
+	JSON    = "json"
+)
+
+func init() {
+	Register(Format{Name: JSON, Decoder: DecoderFunc(decodeJSON), Encoder: EncoderFunc(encodeJSON), Sniff: sniffJSON})
+	Register(Format{Name: Dnsmasq, Decoder: DecoderFunc(decodeDnsmasq), Encoder: EncoderFunc(encodeDnsmasq), Sniff: sniffDnsmasq})
+	Register(Format{Name: Hosts, Decoder: DecoderFunc(parser.Read), Encoder: EncoderFunc(parser.Write), Sniff: sniffHosts})
+}
+
+func sniffHosts(head []byte) bool {
+	line, ok := firstLine(head, "#")
+	if !ok {
+		return true
+	}
+	_, err := parser.ReadFromLine(line)
+	return err == nil
+}
+
+// jsonEntry is an entry of the json format, which is an array of entries.
+type jsonEntry struct {
+	IP        string   `json:"ip"`
+	HostNames []string `json:"host_names"`
+	Comment   string   `json:"comment,omitempty"`
+}
+
+func sniffJSON(head []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(head), []byte("["))
+}
+
+func decodeJSON(reader io.Reader) (hosts.EntrySet, error) {
+	var decoded []jsonEntry
+	if err := json.NewDecoder(reader).Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	entrySet := hosts.NewEntrySet()
+	for _, jsonEntry := range decoded {
+		ip := net.ParseIP(jsonEntry.IP)
+		if ip == nil {
+			return nil, fmt.Errorf("%s: %v", jsonEntry.IP, hosts.ErrorInvalidIp)
+		}
+		entry, err := hosts.NewEntry(ip, jsonEntry.HostNames)
+		if err != nil {
+			return nil, err
+		}
+		entry.SetComment(jsonEntry.Comment)
+		entrySet.AddEntry(entry)
+	}
+	return entrySet, nil
+}
+
+func encodeJSON(entrySet hosts.EntrySet, writer io.Writer) error {
+	encoded := make([]jsonEntry, 0)
+	for _, entry := range sortedEntries(entrySet) {
+		encoded = append(encoded, jsonEntry{IP: entry.IpString(), HostNames: entry.HostNames(), Comment: entry.Comment()})
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(encoded)
+}
+
+// The dnsmasq format consists of 'host-record=name,...,ip' and 'address=/name/.../ip' options,
+// other options are ignored. The address '#' of an address option stands for the null addresses 0.0.0.0 and ::.
+// Records without host names or ips and wildcard records ('address=/#/ip'), which a hosts file cannot express,
+// are skipped.
+const (
+	dnsmasqHostRecord = "host-record="
+	dnsmasqAddress    = "address="
+	// dnsmasqWildcard matches every domain as name of an address option, or stands for the null addresses as its
+	// address.
+	dnsmasqWildcard = "#"
+)
+
+func sniffDnsmasq(head []byte) bool {
+	line, ok := firstLine(head, "#")
+	return ok && (strings.HasPrefix(line, dnsmasqHostRecord) || strings.HasPrefix(line, dnsmasqAddress))
+}
+
+func decodeDnsmasq(reader io.Reader) (hosts.EntrySet, error) {
+	entrySet := hosts.NewEntrySet()
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		var ips []net.IP
+		var hostNames []string
+		switch {
+		case strings.HasPrefix(line, dnsmasqHostRecord):
+			ips, hostNames = parseDnsmasqHostRecord(strings.TrimPrefix(line, dnsmasqHostRecord))
+		case strings.HasPrefix(line, dnsmasqAddress):
+			ips, hostNames = parseDnsmasqAddress(strings.TrimPrefix(line, dnsmasqAddress))
+		default:
+			continue
+		}
+		if len(hostNames) == 0 {
+			continue
+		}
+		for _, ip := range ips {
+			entry, err := hosts.NewEntry(ip, hostNames)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			entrySet.AddEntry(entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entrySet, nil
+}
+
+// parseDnsmasqHostRecord returns the ips and the host names of the value of a host-record option.
+func parseDnsmasqHostRecord(value string) ([]net.IP, []string) {
+	ips, hostNames := make([]net.IP, 0), make([]string, 0)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if ip := net.ParseIP(field); ip != nil {
+			ips = append(ips, ip)
+		} else if field != "" && strings.Trim(field, "0123456789") != "" {
+			// purely numeric fields are the ttl of a host-record
+			hostNames = append(hostNames, field)
+		}
+	}
+	return ips, hostNames
+}
+
+// parseDnsmasqAddress returns the ips and the host names of the value of an address option, a wildcard
+// returns no host names.
+func parseDnsmasqAddress(value string) ([]net.IP, []string) {
+	separator := strings.LastIndex(value, "/")
+	if separator < 0 {
+		return nil, nil
+	}
+
+	hostNames := make([]string, 0)
+	for _, field := range strings.Split(value[:separator], "/") {
+		field = strings.TrimSpace(field)
+		if field == dnsmasqWildcard {
+			return nil, nil
+		}
+		if field != "" {
+			hostNames = append(hostNames, field)
+		}
+	}
+
+	address := strings.TrimSpace(value[separator+1:])
+	if address == dnsmasqWildcard {
+		return []net.IP{net.IPv4zero, net.IPv6zero}, hostNames
+	}
+	if ip := net.ParseIP(address); ip != nil {
+		return []net.IP{ip}, hostNames
+	}
+	return nil, hostNames
+}
+
+func encodeDnsmasq(entrySet hosts.EntrySet, writer io.Writer) error {
+	buffer := &bytes.Buffer{}
+	for _, entry := range sortedEntries(entrySet) {
+		fmt.Fprintf(buffer, "%s%s,%s\n", dnsmasqHostRecord, strings.Join(entry.HostNames(), ","), entry.IpString())
+	}
+	_, err := buffer.WriteTo(writer)
+	return err
+}
@@ -0,0 +1,398 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// An address is the 16 byte form of an ip, used as map key instead of its string.
+type address [net.IPv6len]byte
+
+func toAddress(ip net.IP) address {
+	var a address
+	copy(a[:], ip.To16())
+	return a
+}
+
+func (a address) ip() net.IP {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, a[:])
+	return ip
+}
+
+// compactAddress holds the host names of one ip as sorted ids of a nameTree.
+type compactAddress struct {
+	names   []uint32
+	comment string
+	// origins is only allocated once a host name with origin is added.
+	origins map[uint32]Origin
+}
+
+func (a *compactAddress) index(id uint32) (int, bool) {
+	i := sort.Search(len(a.names), func(i int) bool { return a.names[i] >= id })
+	return i, i < len(a.names) && a.names[i] == id
+}
+
+func (a *compactAddress) add(id uint32) {
+	i, found := a.index(id)
+	if found {
+		return
+	}
+	a.names = append(a.names, 0)
+	copy(a.names[i+1:], a.names[i:])
+	a.names[i] = id
+}
+
+func (a *compactAddress) remove(id uint32) bool {
+	i, found := a.index(id)
+	if !found {
+		return false
+	}
+	a.names = append(a.names[:i], a.names[i+1:]...)
+	delete(a.origins, id)
+	return true
+}
+
+// compactEntrySet keeps the addresses in the order they were added first, like entrySet, removed addresses stay in
+// order until it is compacted.
+type compactEntrySet struct {
+	names     *nameTree
+	addresses map[address]*compactAddress
+	order     []address
+	index     map[address]int
+}
+
+// NewCompactEntrySet returns a MutableEntrySet which needs only a fraction of the memory of NewEntrySet for large sets
+// with few ips, e.g. blocklists which map a million host names to 0.0.0.0. Ips are kept as 16 byte keys and host
+// names are interned in a tree of labels, so names share their common suffixes (e.g. "com" and "example" of
+// every name below example.com). Removed host names stay interned until the set is discarded.
+func NewCompactEntrySet() MutableEntrySet {
+	return &compactEntrySet{
+		names:     newNameTree(),
+		addresses: make(map[address]*compactAddress),
+		order:     make([]address, 0),
+		index:     make(map[address]int),
+	}
+}
+
+func (e *compactEntrySet) AddEntry(entry Entry, entries ...Entry) {
+	e.addEntry(entry)
+	for _, en := range entries {
+		e.addEntry(en)
+	}
+}
+
+func (e *compactEntrySet) addEntry(entry Entry) {
+	key := toAddress(entry.Ip())
+	addr, ok := e.addresses[key]
+	if !ok {
+		addr = &compactAddress{}
+		e.addresses[key] = addr
+		e.index[key] = len(e.order)
+		e.order = append(e.order, key)
+	}
+
+	for _, hostName := range entry.HostNames() {
+		id := e.names.insert(hostName)
+		addr.add(id)
+		origin, ok := entry.Origin(hostName)
+		if !ok {
+			continue
+		}
+		if _, exists := addr.origins[id]; exists {
+			continue
+		}
+		if addr.origins == nil {
+			addr.origins = make(map[uint32]Origin)
+		}
+		addr.origins[id] = origin
+	}
+
+	if comment := entry.Comment(); comment != "" {
+		merged := &simpleEntry{comment: addr.comment}
+		mergeComment(merged, comment)
+		addr.comment = merged.comment
+	}
+}
+
+// RemoveHostName removes the given host name from all ips, ips without host names are removed.
+// It reports whether the host name was found.
+func (e *compactEntrySet) RemoveHostName(hostName string) bool {
+	id, ok := e.names.lookup(strings.ToLower(hostName))
+	if !ok {
+		return false
+	}
+	found := false
+	for key, addr := range e.addresses {
+		if !addr.remove(id) {
+			continue
+		}
+		found = true
+		if len(addr.names) == 0 {
+			e.remove(key)
+		}
+	}
+	return found
+}
+
+// RemoveIP removes the given ip with all its host names and reports whether the ip was found.
+func (e *compactEntrySet) RemoveIP(ip net.IP) bool {
+	key := toAddress(ip)
+	_, found := e.addresses[key]
+	if found {
+		e.remove(key)
+	}
+	return found
+}
+
+func (e *compactEntrySet) remove(key address) {
+	delete(e.addresses, key)
+	delete(e.index, key)
+	if len(e.index) < len(e.order)/2 {
+		e.compact()
+	}
+}
+
+// compact drops the removed addresses from order.
+func (e *compactEntrySet) compact() {
+	order := make([]address, 0, len(e.index))
+	for i, key := range e.order {
+		if e.live(i, key) {
+			e.index[key] = len(order)
+			order = append(order, key)
+		}
+	}
+	e.order = order
+}
+
+// live reports whether the address at position i of order was not removed since.
+func (e *compactEntrySet) live(i int, key address) bool {
+	position, ok := e.index[key]
+	return ok && position == i
+}
+
+func (e *compactEntrySet) Contains(entry Entry) bool {
+	addr, ok := e.addresses[toAddress(entry.Ip())]
+	if !ok {
+		return false
+	}
+	for _, hostName := range entry.HostNames() {
+		id, ok := e.names.lookup(hostName)
+		if !ok {
+			return false
+		}
+		if _, found := addr.index(id); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *compactEntrySet) EntriesOfIP(ip net.IP) (hosts []string, ok bool) {
+	addr, ok := e.addresses[toAddress(ip)]
+	if !ok {
+		return nil, ok
+	}
+	return e.hostNames(addr), true
+}
+
+// LookupHost returns all ips the given host name is mapped to.
+func (e *compactEntrySet) LookupHost(hostName string) (ips []net.IP, ok bool) {
+	id, ok := e.names.lookup(strings.ToLower(hostName))
+	if !ok {
+		return nil, false
+	}
+	for i, key := range e.order {
+		if !e.live(i, key) {
+			continue
+		}
+		if _, found := e.addresses[key].index(id); found {
+			ips = append(ips, key.ip())
+		}
+	}
+	sortIPs(ips)
+	return ips, len(ips) > 0
+}
+
+// AllEntries returns all entries in the order their ips were added first.
+func (e *compactEntrySet) AllEntries() []Entry {
+	entries := make([]Entry, 0, len(e.addresses))
+	for i, key := range e.order {
+		if !e.live(i, key) {
+			continue
+		}
+		addr := e.addresses[key]
+		entry, _ := NewEntryIp(key.ip())
+		for _, id := range addr.names {
+			_ = entry.AddHostName(e.names.name(id))
+		}
+		entry.SetComment(addr.comment)
+		for id, origin := range addr.origins {
+			entry.SetOrigin(e.names.name(id), origin)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// hostNames returns the sorted host names of the given address.
+func (e *compactEntrySet) hostNames(addr *compactAddress) []string {
+	hostNames := make([]string, 0, len(addr.names))
+	for _, id := range addr.names {
+		hostNames = append(hostNames, e.names.name(id))
+	}
+	sort.Strings(hostNames)
+	return hostNames
+}
+
+// A nameTree interns host names as paths of labels starting at the top-level domain, so names share the nodes of
+// their common suffixes. Every node is identified by its index, the root has the index 0. The labels of all nodes
+// are stored in one byte slice and the nodes are found by an open addressing hash table of their indexes, so a node
+// costs a few bytes besides its label.
+type nameTree struct {
+	nodes  []nameNode
+	labels []byte
+	// slots holds the index of the node of every hash slot, 0 for an empty slot.
+	slots []uint32
+}
+
+// nameNode is the label labels[offset:offset+length] of a node below its parent node.
+type nameNode struct {
+	parent uint32
+	offset uint32
+	length uint32
+}
+
+func newNameTree() *nameTree {
+	return &nameTree{
+		nodes:  []nameNode{{}},
+		labels: make([]byte, 0),
+		slots:  make([]uint32, 16),
+	}
+}
+
+// hashLabel returns the FNV-1a hash of the given label below the given parent.
+func hashLabel(parent uint32, label string) uint32 {
+	const prime = 16777619
+	hash := (2166136261 ^ parent) * prime
+	for i := 0; i < len(label); i++ {
+		hash = (hash ^ uint32(label[i])) * prime
+	}
+	return hash
+}
+
+// lastLabel splits the last label from the given host name, more reports whether labels remain.
+func lastLabel(hostName string) (label, rest string, more bool) {
+	i := strings.LastIndexByte(hostName, '.')
+	if i < 0 {
+		return hostName, "", false
+	}
+	return hostName[i+1:], hostName[:i], true
+}
+
+// lookup returns the id of the given host name, if it is interned.
+func (t *nameTree) lookup(hostName string) (id uint32, ok bool) {
+	for more := true; more; {
+		var label string
+		label, hostName, more = lastLabel(hostName)
+		if id = t.slots[t.find(id, label)]; id == 0 {
+			return 0, false
+		}
+	}
+	return id, true
+}
+
+// insert interns the given host name and returns its id.
+func (t *nameTree) insert(hostName string) (id uint32) {
+	for more := true; more; {
+		var label string
+		label, hostName, more = lastLabel(hostName)
+		id = t.addChild(id, label)
+	}
+	return id
+}
+
+// find returns the slot of the child of parent with the given label, the slot is empty if there is no such child.
+func (t *nameTree) find(parent uint32, label string) int {
+	mask := len(t.slots) - 1
+	for i := int(hashLabel(parent, label)) & mask; ; i = (i + 1) & mask {
+		id := t.slots[i]
+		if id == 0 {
+			return i
+		}
+		node := t.nodes[id]
+		if node.parent == parent && string(t.labels[node.offset:node.offset+node.length]) == label {
+			return i
+		}
+	}
+}
+
+func (t *nameTree) addChild(parent uint32, label string) uint32 {
+	slot := t.find(parent, label)
+	if id := t.slots[slot]; id != 0 {
+		return id
+	}
+	id := uint32(len(t.nodes))
+	t.nodes = append(t.nodes, nameNode{parent: parent, offset: uint32(len(t.labels)), length: uint32(len(label))})
+	t.labels = append(t.labels, label...)
+	t.slots[slot] = id
+	if 4*len(t.nodes) > 3*len(t.slots) {
+		t.grow()
+	}
+	return id
+}
+
+// grow doubles the hash table.
+func (t *nameTree) grow() {
+	slots := t.slots
+	t.slots = make([]uint32, 2*len(slots))
+	mask := len(t.slots) - 1
+	for _, id := range slots {
+		if id == 0 {
+			continue
+		}
+		node := t.nodes[id]
+		i := int(hashLabel(node.parent, string(t.labels[node.offset:node.offset+node.length]))) & mask
+		for t.slots[i] != 0 {
+			i = (i + 1) & mask
+		}
+		t.slots[i] = id
+	}
+}
+
+// name returns the host name of the given id.
+func (t *nameTree) name(id uint32) string {
+	builder := strings.Builder{}
+	for first := true; id != 0; first = false {
+		node := t.nodes[id]
+		if !first {
+			builder.WriteByte('.')
+		}
+		builder.Write(t.labels[node.offset : node.offset+node.length])
+		id = node.parent
+	}
+	return builder.String()
+}
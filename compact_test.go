@@ -0,0 +1,183 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// describe returns the entries of the given set with comments and origins as comparable strings.
+func describe(entrySet EntrySet) []string {
+	described := make([]string, 0)
+	for _, entry := range entrySet.AllEntries() {
+		origins := make([]string, 0)
+		for _, hostName := range entry.HostNames() {
+			if origin, ok := entry.Origin(hostName); ok {
+				origins = append(origins, hostName+"@"+origin.String())
+			}
+		}
+		described = append(described, fmt.Sprintf("%s %v # %s %v", entry.IpString(), entry.HostNames(), entry.Comment(), origins))
+	}
+	return described
+}
+
+func TestCompactEntrySet_LikeEntrySet(t *testing.T) {
+	withComment := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"})
+	withComment.SetComment("payments owner=payments")
+	withOrigin := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"web.local", "API.local"})
+	withOrigin.SetComment("web owner=web ticket=OPS-12")
+	withOrigin.SetOrigin("web.local", Origin{Source: "vpn", Path: "/etc/hosts.d/vpn.hosts", Line: 3})
+
+	operations := []struct {
+		name  string
+		apply func(entrySet MutableEntrySet) interface{}
+	}{
+		{name: "add", apply: func(entrySet MutableEntrySet) interface{} {
+			entrySet.AddEntry(
+				NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"ads.example.com", "tracker.example.com", "example.com"}),
+				NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}),
+				NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost", "ip6-localhost"}),
+				withComment, withOrigin,
+				NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"a..b", ".leading", "trailing.", "com"}))
+			return nil
+		}},
+		{name: "contains", apply: func(entrySet MutableEntrySet) interface{} {
+			return []bool{
+				entrySet.Contains(NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"example.com", "ads.example.com"})),
+				entrySet.Contains(NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"other.example.com"})),
+				entrySet.Contains(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"api.local"})),
+				entrySet.Contains(NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"example"})),
+			}
+		}},
+		{name: "entries of ip", apply: func(entrySet MutableEntrySet) interface{} {
+			hostNames, ok := entrySet.EntriesOfIP(net.ParseIP("0.0.0.0"))
+			unknown, unknownOk := entrySet.EntriesOfIP(net.ParseIP("10.9.9.9"))
+			return []interface{}{hostNames, ok, unknown, unknownOk}
+		}},
+		{name: "lookup", apply: func(entrySet MutableEntrySet) interface{} {
+			ips, ok := entrySet.LookupHost("LocalHost")
+			_, unknownOk := entrySet.LookupHost("example")
+			return []interface{}{fmt.Sprint(ips), ok, unknownOk}
+		}},
+		{name: "remove host name", apply: func(entrySet MutableEntrySet) interface{} {
+			return []bool{entrySet.RemoveHostName("localhost"), entrySet.RemoveHostName("localhost"), entrySet.RemoveHostName("example")}
+		}},
+		{name: "remove ip", apply: func(entrySet MutableEntrySet) interface{} {
+			return []bool{entrySet.RemoveIP(net.ParseIP("::1")), entrySet.RemoveIP(net.ParseIP("::1"))}
+		}},
+		{name: "add again", apply: func(entrySet MutableEntrySet) interface{} {
+			entrySet.AddEntry(NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+			return nil
+		}},
+	}
+
+	expectedSet, actualSet := NewEntrySet(), NewCompactEntrySet()
+	for _, operation := range operations {
+		expected, actual := operation.apply(expectedSet), operation.apply(actualSet)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %v, actual %v", operation.name, expected, actual)
+		}
+		if !reflect.DeepEqual(describe(expectedSet), describe(actualSet)) {
+			t.Errorf("%s: expected entries %v, actual %v", operation.name, describe(expectedSet), describe(actualSet))
+		}
+	}
+}
+
+func TestNameTree(t *testing.T) {
+	tree := newNameTree()
+	ids := make(map[string]uint32)
+	for _, hostName := range []string{"example.com", "ads.example.com", "example.org", "com", "", "a..b", ".a", "a."} {
+		ids[hostName] = tree.insert(hostName)
+	}
+	for hostName, id := range ids {
+		if actual := tree.name(id); actual != hostName {
+			t.Errorf("expected name %q of %d, actual %q", hostName, id, actual)
+		}
+		if actual, ok := tree.lookup(hostName); !ok || actual != id {
+			t.Errorf("expected id %d of %q, actual %d", id, hostName, actual)
+		}
+	}
+	if _, ok := tree.lookup("example"); ok {
+		t.Errorf("expected 'example' not to be interned")
+	}
+	// com, example.com, ads.example.com, org, example.org, "", b, "".b, a."".b, a, "".a, a.
+	if len(tree.nodes) != 13 {
+		t.Errorf("expected the suffixes to be shared, actual %d nodes", len(tree.nodes))
+	}
+}
+
+// blocklistEntry returns the i-th entry of a blocklist, which maps all host names to 0.0.0.0.
+func blocklistEntry(i int) Entry {
+	hostName := fmt.Sprintf("ads%d.tracker%d.example%d.com", i, i%100, i%1000)
+	return NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{hostName})
+}
+
+func BenchmarkEntrySet_Memory(b *testing.B) {
+	const size = 100000
+	sets := []struct {
+		name string
+		new  func() MutableEntrySet
+	}{
+		{name: "map", new: NewEntrySet},
+		{name: "compact", new: NewCompactEntrySet},
+	}
+	for _, set := range sets {
+		b.Run(set.name, func(b *testing.B) {
+			var before, after runtime.MemStats
+			retained := make([]EntrySet, 0, b.N)
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			for i := 0; i < b.N; i++ {
+				entrySet := set.new()
+				for j := 0; j < size; j++ {
+					entrySet.AddEntry(blocklistEntry(j))
+				}
+				retained = append(retained, entrySet)
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(b.N*size), "bytes/name")
+			runtime.KeepAlive(retained)
+		})
+	}
+}
+
+func BenchmarkEntrySet_LookupHost(b *testing.B) {
+	for _, set := range []EntrySet{NewEntrySet(), NewCompactEntrySet()} {
+		for i := 0; i < 100000; i++ {
+			set.AddEntry(blocklistEntry(i))
+		}
+		b.Run(fmt.Sprintf("%T", set), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, ok := set.LookupHost("ads4711.tracker11.example711.com"); !ok {
+					b.Fatalf("expected the host name to be found")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// A Conflict is a host name which is mapped to more than one ip of the same address family.
+type Conflict struct {
+	HostName string
+	IPs      []net.IP
+	// Origins holds where the host name was mapped to the ip of the same index, if known.
+	Origins []Origin
+}
+
+func (c Conflict) String() string {
+	ips := make([]string, len(c.IPs))
+	for i, ip := range c.IPs {
+		ips[i] = ip.String()
+		if i < len(c.Origins) && !c.Origins[i].IsZero() {
+			ips[i] = fmt.Sprintf("%s from %v", ips[i], c.Origins[i])
+		}
+	}
+	return fmt.Sprintf("%s => %s", c.HostName, strings.Join(ips, ", "))
+}
+
+// key identifies the conflict by its host name and ips.
+func (c Conflict) key() string {
+	ips := make([]string, len(c.IPs))
+	for i, ip := range c.IPs {
+		ips[i] = ip.String()
+	}
+	sort.Strings(ips)
+	return c.HostName + " " + strings.Join(ips, " ")
+}
+
+// ConflictError is returned when a change would map a host name to more than one ip.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	descriptions := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		descriptions[i] = conflict.String()
+	}
+	return fmt.Sprintf("conflicting host names: %s", strings.Join(descriptions, "; "))
+}
+
+// FindConflicts returns all host names of the given EntrySet which are mapped to more than one ip
+// of the same address family, sorted by host name. A host name with one IPv4 and one IPv6 address is no conflict.
+func FindConflicts(entrySet EntrySet) []Conflict {
+	entriesOfHostName := make(map[string][]Entry)
+	for _, entry := range entrySet.AllEntries() {
+		for _, hostName := range entry.HostNames() {
+			entriesOfHostName[hostName] = append(entriesOfHostName[hostName], entry)
+		}
+	}
+
+	conflicts := make([]Conflict, 0)
+	for hostName, entries := range entriesOfHostName {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].IpString() < entries[j].IpString() })
+		conflict := Conflict{HostName: hostName}
+		for _, entry := range entries {
+			origin, _ := entry.Origin(hostName)
+			conflict.IPs = append(conflict.IPs, entry.Ip())
+			conflict.Origins = append(conflict.Origins, origin)
+		}
+		if hasSameFamily(conflict.IPs) {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].HostName < conflicts[j].HostName })
+	return conflicts
+}
+
+func hasSameFamily(ips []net.IP) bool {
+	ipv4 := 0
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			ipv4++
+		}
+	}
+	return ipv4 > 1 || len(ips)-ipv4 > 1
+}
+
+// Merge adds all entries of src to dst and returns the conflicts caused by the merge,
+// conflicts which already existed in dst or src are not reported again.
+func Merge(dst EntrySet, src EntrySet) []Conflict {
+	known := make(map[string]bool)
+	for _, conflict := range FindConflicts(dst) {
+		known[conflict.HostName] = true
+	}
+	for _, conflict := range FindConflicts(src) {
+		known[conflict.HostName] = true
+	}
+
+	for _, entry := range src.AllEntries() {
+		dst.AddEntry(entry)
+	}
+
+	conflicts := make([]Conflict, 0)
+	for _, conflict := range FindConflicts(dst) {
+		if !known[conflict.HostName] {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,71 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"testing"
+)
+
+func TestFindConflicts(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "api.local"}),
+		NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local"}))
+
+	conflicts := FindConflicts(entries)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, actual %v", conflicts)
+	}
+
+	expected := "api.local => 10.0.0.1, 127.0.0.1"
+	if conflicts[0].String() != expected {
+		t.Errorf("expected conflict '%s', actual '%s'", expected, conflicts[0])
+	}
+
+	err := &ConflictError{Conflicts: conflicts}
+	if err.Error() != "conflicting host names: "+expected {
+		t.Errorf("unexpected error message '%s'", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := NewEntrySet()
+	dst.AddEntry(
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"web.local"}))
+	src := NewEntrySet()
+	src.AddEntry(
+		NewEntryUnsafe(net.ParseIP("10.0.0.3"), []string{"api.local", "web.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.4"), []string{"db.local"}))
+
+	conflicts := Merge(dst, src)
+	if len(conflicts) != 1 || conflicts[0].HostName != "api.local" {
+		t.Errorf("expected only the new conflict of 'api.local', actual %v", conflicts)
+	}
+	if !dst.Contains(NewEntryUnsafe(net.ParseIP("10.0.0.4"), []string{"db.local"})) {
+		t.Errorf("expected %v to contain the merged entries", dst)
+	}
+}
@@ -28,11 +28,14 @@ import (
 	"net"
 	"sort"
 	"strings"
+	"time"
 )
 
 var ErrorNilEntry = errors.New("entry is nil")
 var ErrorInvalidIp = errors.New("invalid ip")
 var ErrorInvalidHostName = errors.New("invalid host-name")
+var ErrorInvalidMetadata = errors.New("invalid metadata")
+var ErrorInvalidTag = errors.New("invalid tag")
 
 // An Entry represent a line in /etc/hosts with multiple hosts associate to one ip.
 type Entry interface {
@@ -40,13 +43,30 @@ type Entry interface {
 	IpString() string
 	HostNames() []string
 	AddHostName(hostName string) error
+	RemoveHostName(hostName string)
 	String() string
 	Contains(hostName string) bool
+	Comment() string
+	SetComment(comment string)
+	Metadata(key string) (value string, ok bool)
+	SetMetadata(key, value string) error
+	RemoveMetadata(key string)
+	ExpiresAt() (expiry time.Time, ok bool)
+	SetExpiresAt(expiry time.Time)
+	Expired(now time.Time) bool
+	Tags() []string
+	HasTag(tag string) bool
+	AddTag(tag string) error
+	RemoveTag(tag string)
+	Origin(hostName string) (origin Origin, ok bool)
+	SetOrigin(hostName string, origin Origin)
 }
 
 type simpleEntry struct {
 	ip        net.IP
 	hostNames map[string]bool
+	comment   string
+	origins   map[string]Origin
 }
 
 func (s *simpleEntry) Contains(hostName string) bool {
@@ -92,6 +112,12 @@ func (s *simpleEntry) AddHostName(hostName string) error {
 	return nil
 }
 
+// RemoveHostName removes the given host name and its origin from the entry.
+func (s *simpleEntry) RemoveHostName(hostName string) {
+	delete(s.hostNames, strings.ToLower(hostName))
+	delete(s.origins, strings.ToLower(hostName))
+}
+
 func (s *simpleEntry) Ip() net.IP {
 	return s.ip
 }
@@ -112,11 +138,186 @@ func (s *simpleEntry) HostNames() []string {
 	return hostNames
 }
 
+// Comment returns the inline comment of the entry without the leading comment sign.
+func (s *simpleEntry) Comment() string {
+	return s.comment
+}
+
+// lineBreakReplacer replaces the line-breaks of a comment by spaces.
+var lineBreakReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// SetComment replaces the inline comment of the entry, line-breaks are replaced by spaces.
+func (s *simpleEntry) SetComment(comment string) {
+	if strings.ContainsAny(comment, "\r\n") {
+		comment = lineBreakReplacer.Replace(comment)
+	}
+	s.comment = strings.TrimSpace(comment)
+}
+
+// Metadata returns the value of the given key from the structured part of the comment (e.g. '# owner=payments').
+func (s *simpleEntry) Metadata(key string) (value string, ok bool) {
+	value, ok = ParseMetadata(s.comment)[key]
+	return value, ok
+}
+
+// SetMetadata sets the given key to the given value inside the comment, replacing an existing value.
+func (s *simpleEntry) SetMetadata(key, value string) error {
+	if !isValidMetadataKey(key) || !isValidMetadataValue(value) {
+		return ErrorInvalidMetadata
+	}
+
+	pair := key + metadataSeparator + value
+	fields := strings.Fields(s.comment)
+	for i, field := range fields {
+		if k, _, ok := splitMetadata(field); ok && k == key {
+			fields[i] = pair
+			s.comment = strings.Join(fields, " ")
+			return nil
+		}
+	}
+	s.comment = strings.Join(append(fields, pair), " ")
+	return nil
+}
+
+// RemoveMetadata removes the given key and its value from the comment.
+func (s *simpleEntry) RemoveMetadata(key string) {
+	fields := strings.Fields(s.comment)
+	kept := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if k, _, ok := splitMetadata(field); ok && k == key {
+			continue
+		}
+		kept = append(kept, field)
+	}
+	if len(kept) != len(fields) {
+		s.comment = strings.Join(kept, " ")
+	}
+}
+
+// ExpiresAt returns the expiry time stored in the 'expires' metadata of the comment.
+func (s *simpleEntry) ExpiresAt() (expiry time.Time, ok bool) {
+	value, ok := s.Metadata(MetadataExpires)
+	if !ok {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// SetExpiresAt stores the given expiry time in the 'expires' metadata of the comment, a zero time removes it.
+func (s *simpleEntry) SetExpiresAt(expiry time.Time) {
+	if expiry.IsZero() {
+		s.RemoveMetadata(MetadataExpires)
+		return
+	}
+	_ = s.SetMetadata(MetadataExpires, expiry.UTC().Format(time.RFC3339))
+}
+
+// Expired reports whether the entry has an expiry time which is not after now.
+func (s *simpleEntry) Expired(now time.Time) bool {
+	expiry, ok := s.ExpiresAt()
+	return ok && !expiry.After(now)
+}
+
+// Tags returns the sorted tags stored in the 'tags' metadata of the comment (e.g. 'tags=billing,dev').
+func (s *simpleEntry) Tags() []string {
+	value, ok := s.Metadata(MetadataTags)
+	if !ok {
+		return nil
+	}
+	return splitTags(value)
+}
+
+// HasTag reports whether the entry is tagged with the given tag.
+func (s *simpleEntry) HasTag(tag string) bool {
+	for _, t := range s.Tags() {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds the given tag to the 'tags' metadata of the comment.
+func (s *simpleEntry) AddTag(tag string) error {
+	if !isValidTag(tag) {
+		return ErrorInvalidTag
+	}
+	if s.HasTag(tag) {
+		return nil
+	}
+	return s.setTags(append(s.Tags(), tag))
+}
+
+// RemoveTag removes the given tag from the 'tags' metadata of the comment, the metadata is removed with the last tag.
+func (s *simpleEntry) RemoveTag(tag string) {
+	tags := make([]string, 0)
+	for _, t := range s.Tags() {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	_ = s.setTags(tags)
+}
+
+func (s *simpleEntry) setTags(tags []string) error {
+	if len(tags) == 0 {
+		s.RemoveMetadata(MetadataTags)
+		return nil
+	}
+	sort.Strings(tags)
+	return s.SetMetadata(MetadataTags, strings.Join(tags, tagSeparator))
+}
+
+// Origin returns where the given host name of the entry was defined.
+func (s *simpleEntry) Origin(hostName string) (origin Origin, ok bool) {
+	origin, ok = s.origins[strings.ToLower(hostName)]
+	return origin, ok
+}
+
+// SetOrigin records where the given host name of the entry was defined, unknown host names are ignored.
+func (s *simpleEntry) SetOrigin(hostName string, origin Origin) {
+	hostName = strings.ToLower(hostName)
+	if !s.Contains(hostName) {
+		return
+	}
+	if origin.IsZero() {
+		delete(s.origins, hostName)
+		return
+	}
+	if s.origins == nil {
+		s.origins = make(map[string]Origin)
+	}
+	s.origins[hostName] = origin
+}
+
+// CloneEntry returns a copy of the entry including its comment and the origins of its host names.
 func CloneEntry(entry Entry) (Entry, error) {
 	if entry == nil {
 		return nil, ErrorNilEntry
 	}
-	return NewEntry(entry.Ip(), entry.HostNames())
+	clone, err := NewEntry(entry.Ip(), entry.HostNames())
+	if err != nil {
+		return nil, err
+	}
+	clone.SetComment(entry.Comment())
+	copyOrigins(clone, entry)
+	return clone, nil
+}
+
+// copyOrigins copies the origins of all host names of src which have no origin in dst yet.
+func copyOrigins(dst Entry, src Entry) {
+	for _, hostName := range src.HostNames() {
+		if _, ok := dst.Origin(hostName); ok {
+			continue
+		}
+		if origin, ok := src.Origin(hostName); ok {
+			dst.SetOrigin(hostName, origin)
+		}
+	}
 }
 
 func NewEntryUnsafe(ip net.IP, hosts []string) Entry {
@@ -22,17 +22,40 @@
 
 package hosts
 
-import "net"
+import (
+	"net"
+	"sort"
+	"strings"
+)
 
-type EntrySet interface {
-	AddEntry(entry Entry, entries ...Entry)
+// A ReadOnlyEntrySet resolves host names and ips, entries cannot be added to it.
+type ReadOnlyEntrySet interface {
 	Contains(entry Entry) bool
 	EntriesOfIP(ip net.IP) (hosts []string, ok bool)
+	LookupHost(hostName string) (ips []net.IP, ok bool)
 	AllEntries() []Entry
 }
 
+type EntrySet interface {
+	ReadOnlyEntrySet
+	AddEntry(entry Entry, entries ...Entry)
+}
+
+// A MutableEntrySet is an EntrySet whose host names and ips can be removed again.
+type MutableEntrySet interface {
+	EntrySet
+	// RemoveHostName removes the given host name from all ips and reports whether it was found.
+	RemoveHostName(hostName string) bool
+	// RemoveIP removes the given ip with all its host names and reports whether it was found.
+	RemoveIP(ip net.IP) bool
+}
+
+// entrySet keeps the entries in the order their ips were added first. index holds the position of every ip in
+// order, removed ips stay in order until it is compacted.
 type entrySet struct {
 	entries map[string]Entry
+	order   []string
+	index   map[string]int
 }
 
 func (e *entrySet) AddEntry(entry Entry, entries ...Entry) {
@@ -48,6 +71,85 @@ func (e *entrySet) addEntry(entry Entry) {
 	for _, h := range entry.HostNames() {
 		en.AddHostName(h)
 	}
+	mergeComment(en, entry.Comment())
+	copyOrigins(en, entry)
+}
+
+// mergeComment keeps the comment of the entry and adds only the metadata keys it does not have yet,
+// tags of both comments are combined.
+func mergeComment(entry Entry, comment string) {
+	if entry.Comment() == "" {
+		entry.SetComment(comment)
+		return
+	}
+	for _, field := range strings.Fields(comment) {
+		key, value, ok := splitMetadata(field)
+		if !ok {
+			continue
+		}
+		if key == MetadataTags {
+			for _, tag := range splitTags(value) {
+				_ = entry.AddTag(tag)
+			}
+			continue
+		}
+		if _, exists := entry.Metadata(key); !exists {
+			_ = entry.SetMetadata(key, value)
+		}
+	}
+}
+
+// RemoveHostName removes the given host name from all ips, ips without host names are removed.
+// It reports whether the host name was found.
+func (e *entrySet) RemoveHostName(hostName string) bool {
+	hostName = strings.ToLower(hostName)
+	found := false
+	for key, ent := range e.entries {
+		if !ent.Contains(hostName) {
+			continue
+		}
+		found = true
+		ent.RemoveHostName(hostName)
+		if len(ent.HostNames()) == 0 {
+			e.remove(key)
+		}
+	}
+	return found
+}
+
+// RemoveIP removes the given ip with all its host names and reports whether the ip was found.
+func (e *entrySet) RemoveIP(ip net.IP) bool {
+	_, found := e.entries[ip.String()]
+	if found {
+		e.remove(ip.String())
+	}
+	return found
+}
+
+func (e *entrySet) remove(key string) {
+	delete(e.entries, key)
+	delete(e.index, key)
+	if len(e.index) < len(e.order)/2 {
+		e.compact()
+	}
+}
+
+// compact drops the removed ips from order.
+func (e *entrySet) compact() {
+	order := make([]string, 0, len(e.index))
+	for i, key := range e.order {
+		if e.live(i, key) {
+			e.index[key] = len(order)
+			order = append(order, key)
+		}
+	}
+	e.order = order
+}
+
+// live reports whether the ip at position i of order was not removed since.
+func (e *entrySet) live(i int, key string) bool {
+	position, ok := e.index[key]
+	return ok && position == i
 }
 
 func (e *entrySet) Contains(entry Entry) bool {
@@ -74,10 +176,26 @@ func (e *entrySet) EntriesOfIP(ip net.IP) (hosts []string, ok bool) {
 	return hosts, true
 }
 
-func (e *entrySet) AllEntries() []Entry {
-	entries := make([]Entry, 0)
+// LookupHost returns all ips the given host name is mapped to.
+func (e *entrySet) LookupHost(hostName string) (ips []net.IP, ok bool) {
+	hostName = strings.ToLower(hostName)
 	for _, ent := range e.entries {
-		entry, _ := CloneEntry(ent)
+		if ent.Contains(hostName) {
+			ips = append(ips, ent.Ip())
+		}
+	}
+	sortIPs(ips)
+	return ips, len(ips) > 0
+}
+
+// AllEntries returns copies of all entries in the order their ips were added first.
+func (e *entrySet) AllEntries() []Entry {
+	entries := make([]Entry, 0, len(e.entries))
+	for i, key := range e.order {
+		if !e.live(i, key) {
+			continue
+		}
+		entry, _ := CloneEntry(e.entries[key])
 		entries = append(entries, entry)
 	}
 	return entries
@@ -88,12 +206,22 @@ func (e *entrySet) getOrCreateEntry(ip net.IP) Entry {
 	if !ok {
 		en, _ = NewEntryIp(ip)
 		e.entries[en.IpString()] = en
+		e.index[en.IpString()] = len(e.order)
+		e.order = append(e.order, en.IpString())
 	}
 	return en
 }
 
-func NewEntrySet() EntrySet {
+func NewEntrySet() MutableEntrySet {
 	return &entrySet{
 		entries: make(map[string]Entry),
+		order:   make([]string, 0),
+		index:   make(map[string]int),
 	}
 }
+
+// sortIPs sorts the given ips by their numeric value rather than their string representation, loopback ips first
+// and IPv4 before IPv6 ips, see CompareIPs.
+func sortIPs(ips []net.IP) {
+	sort.Slice(ips, func(i, j int) bool { return CompareIPs(ips[i], ips[j]) < 0 })
+}
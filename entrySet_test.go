@@ -23,7 +23,9 @@
 package hosts
 
 import (
+	"fmt"
 	"net"
+	"strings"
 	"testing"
 )
 
@@ -149,3 +151,130 @@ func TestContains(t *testing.T) {
 	}
 
 }
+
+func TestEntrySet_AddEntryMergesComment(t *testing.T) {
+	first := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"hallo"})
+	first.SetComment("first owner=payments")
+	second := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"hello"})
+	second.SetComment("second owner=billing ticket=OPS-12")
+
+	entries := NewEntrySet()
+	entries.AddEntry(first, second)
+
+	comment := entries.AllEntries()[0].Comment()
+	expected := "first owner=payments ticket=OPS-12"
+	if comment != expected {
+		t.Errorf("expected comment '%s', actual '%s'", expected, comment)
+	}
+}
+
+func TestEntrySet_LookupHost(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}),
+		NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"}))
+
+	ips, ok := entries.LookupHost("LocalHost")
+	if !ok || len(ips) != 2 || ips[0].String() != "127.0.0.1" || ips[1].String() != "::1" {
+		t.Errorf("unexpected ips %v of 'localhost'", ips)
+	}
+
+	if _, ok := entries.LookupHost("unknown.local"); ok {
+		t.Errorf("expected 'unknown.local' not to be found")
+	}
+}
+
+func TestEntrySet_RemoveHostName(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "api.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"}))
+
+	if !entries.RemoveHostName("API.local") {
+		t.Errorf("expected 'api.local' to be removed")
+	}
+	if entries.RemoveHostName("api.local") {
+		t.Errorf("expected 'api.local' to be removed only once")
+	}
+	if len(entries.AllEntries()) != 1 {
+		t.Errorf("expected ip without host names to be removed, actual %v", entries.AllEntries())
+	}
+}
+
+func TestEntrySet_RemoveIP(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	if !entries.RemoveIP(net.ParseIP("127.0.0.1")) || entries.RemoveIP(net.ParseIP("127.0.0.1")) {
+		t.Errorf("expected 127.0.0.1 to be removed once")
+	}
+	if len(entries.AllEntries()) != 0 {
+		t.Errorf("expected an empty set, actual %v", entries.AllEntries())
+	}
+}
+
+func TestEntrySet_AllEntriesInInsertionOrder(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"b.local"}),
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"a.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"c.local"}))
+	entries.RemoveHostName("localhost")
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	actual := make([]string, 0)
+	for _, entry := range entries.AllEntries() {
+		actual = append(actual, entry.IpString())
+	}
+	if strings.Join(actual, " ") != "10.0.0.2 10.0.0.1 127.0.0.1" {
+		t.Errorf("expected the ips in insertion order, actual %v", actual)
+	}
+}
+
+func TestEntrySet_RemoveKeepsInsertionOrder(t *testing.T) {
+	for name, newEntrySet := range map[string]func() MutableEntrySet{"map": NewEntrySet, "compact": NewCompactEntrySet} {
+		t.Run(name, func(t *testing.T) {
+			entries := newEntrySet()
+			for i := 1; i <= 10; i++ {
+				entries.AddEntry(NewEntryUnsafe(net.ParseIP(fmt.Sprintf("10.0.0.%d", i)), []string{fmt.Sprintf("host%d", i)}))
+			}
+			// more than half of the ips are removed, the order is compacted
+			for _, i := range []int{1, 2, 4, 5, 7, 8, 9} {
+				entries.RemoveIP(net.ParseIP(fmt.Sprintf("10.0.0.%d", i)))
+			}
+			entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"host2"}))
+			entries.RemoveIP(net.ParseIP("10.0.0.6"))
+			entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.6"), []string{"host6"}))
+
+			actual := make([]string, 0)
+			for _, entry := range entries.AllEntries() {
+				actual = append(actual, entry.IpString())
+			}
+			if strings.Join(actual, " ") != "10.0.0.3 10.0.0.10 10.0.0.2 10.0.0.6" {
+				t.Errorf("expected the ips in insertion order, actual %v", actual)
+			}
+			if ips, _ := entries.LookupHost("host6"); len(ips) != 1 {
+				t.Errorf("expected one ip of host6, actual %v", ips)
+			}
+		})
+	}
+}
+
+func BenchmarkEntrySet_RemoveIP(b *testing.B) {
+	ips := make([]net.IP, 10000)
+	for i := range ips {
+		ips[i] = net.IPv4(10, 0, byte(i>>8), byte(i))
+	}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		entries := NewEntrySet()
+		for _, ip := range ips {
+			entries.AddEntry(NewEntryUnsafe(ip, []string{"host.local"}))
+		}
+		for _, ip := range ips {
+			entries.RemoveIP(ip)
+		}
+	}
+}
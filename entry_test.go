@@ -26,6 +26,7 @@ import (
 	"net"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewEntryIpWithNilIp(t *testing.T) {
@@ -109,3 +110,187 @@ func TestSimpleEntry_AddHostNameInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestSimpleEntry_SetComment(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetComment("  first line\nsecond line ")
+
+	expected := "first line second line"
+	if entry.Comment() != expected {
+		t.Errorf("expected comment '%s', actual '%s'", expected, entry.Comment())
+	}
+}
+
+func TestSimpleEntry_Metadata(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetComment("payments api owner=payments ticket=OPS-12")
+
+	tests := []struct {
+		key   string
+		value string
+		ok    bool
+	}{
+		{key: "owner", value: "payments", ok: true},
+		{key: "ticket", value: "OPS-12", ok: true},
+		{key: "payments", value: "", ok: false},
+		{key: "unknown", value: "", ok: false},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			value, ok := entry.Metadata(test.key)
+			if value != test.value || ok != test.ok {
+				t.Errorf("expected Metadata('%s')=('%s', %v), actual ('%s', %v)", test.key, test.value, test.ok, value, ok)
+			}
+		})
+	}
+}
+
+func TestSimpleEntry_SetMetadata(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetComment("payments api owner=payments")
+
+	if err := entry.SetMetadata("owner", "billing"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := entry.SetMetadata("ticket", "OPS-12"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := "payments api owner=billing ticket=OPS-12"
+	if entry.Comment() != expected {
+		t.Errorf("expected comment '%s', actual '%s'", expected, entry.Comment())
+	}
+
+	entry.RemoveMetadata("owner")
+	expected = "payments api ticket=OPS-12"
+	if entry.Comment() != expected {
+		t.Errorf("expected comment '%s', actual '%s'", expected, entry.Comment())
+	}
+}
+
+func TestSimpleEntry_SetMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{key: "", value: "payments"},
+		{key: "own er", value: "payments"},
+		{key: "own=er", value: "payments"},
+		{key: "owner", value: "pay ments"},
+	}
+	for _, test := range tests {
+		entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+		t.Run(test.key+"="+test.value, func(t *testing.T) {
+			err := entry.SetMetadata(test.key, test.value)
+			if err != ErrorInvalidMetadata {
+				t.Errorf("expected error: '%#v', but actual: '%#v'", ErrorInvalidMetadata, err)
+			}
+		})
+	}
+}
+
+func TestCloneEntryKeepsComment(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetComment("owner=payments")
+
+	clone, err := CloneEntry(entry)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if clone.Comment() != entry.Comment() {
+		t.Errorf("expected comment '%s', actual '%s'", entry.Comment(), clone.Comment())
+	}
+}
+
+func TestSimpleEntry_ExpiresAt(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	if _, ok := entry.ExpiresAt(); ok {
+		t.Errorf("expected no expiry for '%v'", entry)
+	}
+
+	expiry := time.Date(2020, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	entry.SetExpiresAt(expiry)
+	if entry.Comment() != "expires=2020-05-01T12:00:00Z" {
+		t.Errorf("unexpected comment '%s'", entry.Comment())
+	}
+
+	actual, ok := entry.ExpiresAt()
+	if !ok || !actual.Equal(expiry) {
+		t.Errorf("expected expiry %v, actual %v", expiry, actual)
+	}
+
+	entry.SetExpiresAt(time.Time{})
+	if _, ok := entry.ExpiresAt(); ok || entry.Comment() != "" {
+		t.Errorf("expected expiry to be removed from '%s'", entry.Comment())
+	}
+}
+
+func TestSimpleEntry_Expired(t *testing.T) {
+	expiry := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetExpiresAt(expiry)
+
+	tests := []struct {
+		now     time.Time
+		expired bool
+	}{
+		{now: expiry.Add(-time.Second), expired: false},
+		{now: expiry, expired: true},
+		{now: expiry.Add(time.Second), expired: true},
+	}
+	for _, test := range tests {
+		t.Run(test.now.String(), func(t *testing.T) {
+			if entry.Expired(test.now) != test.expired {
+				t.Errorf("expected Expired(%v)=%v", test.now, test.expired)
+			}
+		})
+	}
+
+	entry.SetComment("expires=tomorrow")
+	if entry.Expired(expiry) {
+		t.Errorf("expected an invalid expiry to be ignored")
+	}
+}
+
+func TestSimpleEntry_Tags(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	entry.SetComment("billing api")
+
+	for _, tag := range []string{"staging", "billing", "staging"} {
+		if err := entry.AddTag(tag); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if entry.Comment() != "billing api tags=billing,staging" {
+		t.Errorf("unexpected comment '%s'", entry.Comment())
+	}
+	if !entry.HasTag("staging") || entry.HasTag("dev") {
+		t.Errorf("unexpected tags %v", entry.Tags())
+	}
+
+	entry.RemoveTag("staging")
+	entry.RemoveTag("billing")
+	if entry.Comment() != "billing api" || entry.Tags() != nil {
+		t.Errorf("expected no tags in comment '%s'", entry.Comment())
+	}
+}
+
+func TestSimpleEntry_AddTagInvalid(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	for _, tag := range []string{"", "dev,staging", "dev staging"} {
+		t.Run(tag, func(t *testing.T) {
+			if err := entry.AddTag(tag); err != ErrorInvalidTag {
+				t.Errorf("expected error: '%#v', but actual: '%#v'", ErrorInvalidTag, err)
+			}
+		})
+	}
+}
+
+func TestSimpleEntry_RemoveHostName(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "example.com"})
+	entry.RemoveHostName("LOCALHOST")
+
+	if entry.Contains("localhost") || !entry.Contains("example.com") {
+		t.Errorf("unexpected host names %v", entry.HostNames())
+	}
+}
@@ -0,0 +1,192 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/bitofcode/hosts"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Compile writes the host names and ips of the given entry set as database into the given io.Writer.
+// Comments and origins of the entries are not part of the database, entries without valid ip are skipped.
+func Compile(entrySet hosts.EntrySet, writer io.Writer) error {
+	ipNames := make(map[string]map[string]bool)
+	nameSet := make(map[string]bool)
+	for _, entry := range entrySet.AllEntries() {
+		ip := entry.Ip().To16()
+		if ip == nil {
+			continue
+		}
+		key := string(ip)
+		if ipNames[key] == nil {
+			ipNames[key] = make(map[string]bool)
+		}
+		for _, hostName := range entry.HostNames() {
+			ipNames[key][hostName] = true
+			nameSet[hostName] = true
+		}
+	}
+
+	ipKeys := make([]string, 0, len(ipNames))
+	for key := range ipNames {
+		ipKeys = append(ipKeys, key)
+	}
+	sort.Strings(ipKeys)
+	names := make([]string, 0, len(nameSet))
+	for hostName := range nameSet {
+		names = append(names, hostName)
+	}
+	sort.Strings(names)
+
+	nameIndexes := make(map[string]uint32, len(names))
+	stringsSize := 0
+	for i, hostName := range names {
+		nameIndexes[hostName] = uint32(i)
+		stringsSize += len(hostName)
+	}
+	refCount := 0
+	for _, hostNames := range ipNames {
+		refCount += len(hostNames)
+	}
+	slotCount := uint64(2)
+	for slotCount < 2*uint64(len(names)) {
+		slotCount *= 2
+	}
+	if uint64(stringsSize) > math.MaxUint32 || uint64(refCount) > math.MaxUint32 || slotCount > math.MaxUint32 {
+		return ErrorTooLarge
+	}
+
+	// ips and ipNames, collecting the ips of every name on the way
+	ips := &bytes.Buffer{}
+	ipNamesSection := &bytes.Buffer{}
+	nameIPs := make([][]uint32, len(names))
+	for ipIndex, key := range ipKeys {
+		indexes := make([]uint32, 0, len(ipNames[key]))
+		for hostName := range ipNames[key] {
+			indexes = append(indexes, nameIndexes[hostName])
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+		ips.WriteString(key)
+		writeUint32(ips, uint32(ipNamesSection.Len()/4), uint32(len(indexes)))
+		for _, index := range indexes {
+			writeUint32(ipNamesSection, index)
+			nameIPs[index] = append(nameIPs[index], uint32(ipIndex))
+		}
+	}
+
+	// names, nameIPs, slots and strings
+	namesSection := &bytes.Buffer{}
+	nameIPsSection := &bytes.Buffer{}
+	stringsSection := &bytes.Buffer{}
+	slots := make([]uint32, slotCount)
+	for index, hostName := range names {
+		indexes := nameIPs[index]
+		sort.Slice(indexes, func(i, j int) bool {
+			return hosts.CompareIPs(net.IP(ipKeys[indexes[i]]), net.IP(ipKeys[indexes[j]])) < 0
+		})
+		writeUint32(namesSection, uint32(stringsSection.Len()), uint32(len(hostName)),
+			uint32(nameIPsSection.Len()/4), uint32(len(indexes)))
+		writeUint32(nameIPsSection, indexes...)
+		stringsSection.WriteString(hostName)
+
+		slot := hashName(hostName) & uint32(slotCount-1)
+		for slots[slot] != 0 {
+			slot = (slot + 1) & uint32(slotCount-1)
+		}
+		slots[slot] = uint32(index) + 1
+	}
+	slotsSection := &bytes.Buffer{}
+	writeUint32(slotsSection, slots...)
+
+	h := &header{
+		version:   Version,
+		ipCount:   uint32(len(ipKeys)),
+		nameCount: uint32(len(names)),
+		slotCount: uint32(slotCount),
+		refCount:  uint32(refCount),
+	}
+	body := &bytes.Buffer{}
+	sections := []struct {
+		offset  *uint64
+		content *bytes.Buffer
+	}{
+		{offset: &h.ips, content: ips},
+		{offset: &h.ipNames, content: ipNamesSection},
+		{offset: &h.names, content: namesSection},
+		{offset: &h.nameIPs, content: nameIPsSection},
+		{offset: &h.slots, content: slotsSection},
+		{offset: &h.strings, content: stringsSection},
+	}
+	for _, section := range sections {
+		*section.offset = uint64(headerSize + body.Len())
+		body.Write(section.content.Bytes())
+	}
+	h.checksum = crc32.Checksum(body.Bytes(), castagnoli)
+
+	if _, err := writer.Write(h.encode()); err != nil {
+		return err
+	}
+	_, err := body.WriteTo(writer)
+	return err
+}
+
+func writeUint32(buffer *bytes.Buffer, values ...uint32) {
+	var encoded [4]byte
+	for _, value := range values {
+		binary.LittleEndian.PutUint32(encoded[:], value)
+		buffer.Write(encoded[:])
+	}
+}
+
+// WriteFile compiles the given entry set into the database file at the given path. The file is replaced atomically,
+// so a process which opened the former database keeps reading it.
+func WriteFile(entrySet hosts.EntrySet, path string) error {
+	temp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := Compile(entrySet, temp); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Chmod(0644); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
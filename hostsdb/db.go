@@ -0,0 +1,339 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Version is the version of the file format written by Compile.
+const Version = 1
+
+const (
+	magic      = "HOSTSDB\x00"
+	headerSize = 80
+	ipSize     = net.IPv6len + 8
+	nameSize   = 16
+)
+
+var (
+	ErrorInvalidFormat      = errors.New("invalid hosts database")
+	ErrorChecksum           = errors.New("hosts database checksum mismatch")
+	ErrorUnsupportedVersion = errors.New("unsupported hosts database version")
+	ErrorTooLarge           = errors.New("entry set too large for a hosts database")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// header is the decoded header of a database.
+type header struct {
+	version   uint32
+	checksum  uint32
+	ipCount   uint32
+	nameCount uint32
+	slotCount uint32
+	refCount  uint32
+	ips       uint64
+	ipNames   uint64
+	names     uint64
+	nameIPs   uint64
+	slots     uint64
+	strings   uint64
+}
+
+func (h *header) encode() []byte {
+	buffer := make([]byte, headerSize)
+	copy(buffer, magic)
+	values := []uint32{h.version, h.checksum, h.ipCount, h.nameCount, h.slotCount, h.refCount}
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(buffer[8+4*i:], value)
+	}
+	offsets := []uint64{h.ips, h.ipNames, h.names, h.nameIPs, h.slots, h.strings}
+	for i, offset := range offsets {
+		binary.LittleEndian.PutUint64(buffer[32+8*i:], offset)
+	}
+	return buffer
+}
+
+func decodeHeader(data []byte) (*header, error) {
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, ErrorInvalidFormat
+	}
+	u32 := func(i int) uint32 { return binary.LittleEndian.Uint32(data[8+4*i:]) }
+	u64 := func(i int) uint64 { return binary.LittleEndian.Uint64(data[32+8*i:]) }
+	h := &header{
+		version: u32(0), checksum: u32(1), ipCount: u32(2), nameCount: u32(3), slotCount: u32(4), refCount: u32(5),
+		ips: u64(0), ipNames: u64(1), names: u64(2), nameIPs: u64(3), slots: u64(4), strings: u64(5),
+	}
+	if h.version != Version {
+		return nil, fmt.Errorf("%w %d", ErrorUnsupportedVersion, h.version)
+	}
+	return h, nil
+}
+
+// A DB is a read-only hosts database. It is safe for concurrent use.
+type DB struct {
+	data    []byte
+	header  *header
+	ips     []byte
+	ipNames []byte
+	names   []byte
+	nameIPs []byte
+	slots   []byte
+	strings []byte
+	close   func() error
+}
+
+// Open maps the database file at the given path into memory, on systems without mmap it is read.
+// Like New only the header is checked, Verify checks the whole database.
+func Open(path string) (*DB, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := New(data)
+	if err != nil {
+		_ = unmap()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	db.close = unmap
+	return db, nil
+}
+
+// New returns the database of the given content, which must not be modified while the DB is used.
+// Only the header and the bounds of the sections are checked, so opening a huge database does not read it.
+// Queries check the records they read and never read beyond the data, but a damaged database may answer
+// them wrong, Verify checks the checksum and all records.
+func New(data []byte) (*DB, error) {
+	h, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.slotCount == 0 || h.slotCount&(h.slotCount-1) != 0 || h.slotCount <= h.nameCount {
+		return nil, ErrorInvalidFormat
+	}
+
+	db := &DB{data: data, header: h}
+	sections := []struct {
+		section *[]byte
+		offset  uint64
+		size    uint64
+	}{
+		{section: &db.ips, offset: h.ips, size: uint64(h.ipCount) * ipSize},
+		{section: &db.ipNames, offset: h.ipNames, size: uint64(h.refCount) * 4},
+		{section: &db.names, offset: h.names, size: uint64(h.nameCount) * nameSize},
+		{section: &db.nameIPs, offset: h.nameIPs, size: uint64(h.refCount) * 4},
+		{section: &db.slots, offset: h.slots, size: uint64(h.slotCount) * 4},
+		{section: &db.strings, offset: h.strings, size: uint64(len(data)) - h.strings},
+	}
+	for _, s := range sections {
+		if s.offset < headerSize || s.offset > uint64(len(data)) || s.size > uint64(len(data))-s.offset {
+			return nil, ErrorInvalidFormat
+		}
+		*s.section = data[s.offset : s.offset+s.size]
+	}
+	return db, nil
+}
+
+// Verify checks the checksum of the database and that all indexes and ranges of the records are within
+// their sections. ErrorChecksum or ErrorInvalidFormat is returned for a damaged database.
+func (db *DB) Verify() error {
+	h := db.header
+	if crc32.Checksum(db.data[headerSize:], castagnoli) != h.checksum {
+		return ErrorChecksum
+	}
+	for i := uint32(0); i < h.ipCount; i++ {
+		first, count := db.ipRecord(i)
+		if !validRange(first, count, h.refCount) {
+			return ErrorInvalidFormat
+		}
+	}
+	for i := uint32(0); i < h.nameCount; i++ {
+		offset, length, first, count := db.nameRecord(i)
+		if !validRange(offset, length, uint32(len(db.strings))) || !validRange(first, count, h.refCount) {
+			return ErrorInvalidFormat
+		}
+	}
+	for i := uint32(0); i < h.refCount; i++ {
+		if db.ref(db.ipNames, i) >= h.nameCount || db.ref(db.nameIPs, i) >= h.ipCount {
+			return ErrorInvalidFormat
+		}
+	}
+	for i := uint32(0); i < h.slotCount; i++ {
+		if db.ref(db.slots, i) > h.nameCount {
+			return ErrorInvalidFormat
+		}
+	}
+	return nil
+}
+
+// Close releases the mapped file, the DB must not be used afterwards.
+func (db *DB) Close() error {
+	if db.close == nil {
+		return nil
+	}
+	closeDB := db.close
+	db.close = nil
+	return closeDB()
+}
+
+// Version returns the format version of the database.
+func (db *DB) Version() int {
+	return int(db.header.version)
+}
+
+// Len returns the number of host names in the database.
+func (db *DB) Len() int {
+	return int(db.header.nameCount)
+}
+
+// LookupHost returns all ips the given host name is mapped to, ordered by hosts.CompareIPs.
+func (db *DB) LookupHost(hostName string) (ips []net.IP, ok bool) {
+	index, ok := db.findName(strings.ToLower(hostName))
+	if !ok {
+		return nil, false
+	}
+	_, _, first, count := db.nameRecord(index)
+	if !validRange(first, count, db.header.refCount) {
+		return nil, false
+	}
+	ips = make([]net.IP, 0, count)
+	for i := first; i < first+count; i++ {
+		ip := db.ref(db.nameIPs, i)
+		if ip >= db.header.ipCount {
+			return nil, false
+		}
+		ips = append(ips, db.ip(ip))
+	}
+	return ips, true
+}
+
+// EntriesOfIP returns the host names of the given ip in alphabetical order.
+func (db *DB) EntriesOfIP(ip net.IP) (hostNames []string, ok bool) {
+	key := ip.To16()
+	if key == nil {
+		return nil, false
+	}
+	count := int(db.header.ipCount)
+	index := sort.Search(count, func(i int) bool {
+		return bytes.Compare(db.ipKey(uint32(i)), key) >= 0
+	})
+	if index == count || !bytes.Equal(db.ipKey(uint32(index)), key) {
+		return nil, false
+	}
+	first, n := db.ipRecord(uint32(index))
+	if !validRange(first, n, db.header.refCount) {
+		return nil, false
+	}
+	hostNames = make([]string, 0, n)
+	for i := first; i < first+n; i++ {
+		hostName, ok := db.name(db.ref(db.ipNames, i))
+		if !ok {
+			return nil, false
+		}
+		hostNames = append(hostNames, hostName)
+	}
+	return hostNames, true
+}
+
+// findName returns the index of the given lower case host name. The probe stops after all slots were visited,
+// a damaged database may have no empty slot.
+func (db *DB) findName(hostName string) (uint32, bool) {
+	mask := db.header.slotCount - 1
+	slot := hashName(hostName) & mask
+	for probe := uint32(0); probe < db.header.slotCount; probe++ {
+		entry := db.ref(db.slots, slot)
+		if entry == 0 {
+			return 0, false
+		}
+		if name, ok := db.nameBytes(entry - 1); ok && string(name) == hostName {
+			return entry - 1, true
+		}
+		slot = (slot + 1) & mask
+	}
+	return 0, false
+}
+
+func (db *DB) ref(section []byte, i uint32) uint32 {
+	return binary.LittleEndian.Uint32(section[4*i:])
+}
+
+func (db *DB) ipKey(i uint32) []byte {
+	return db.ips[i*ipSize : i*ipSize+net.IPv6len]
+}
+
+func (db *DB) ipRecord(i uint32) (first, count uint32) {
+	record := db.ips[i*ipSize+net.IPv6len:]
+	return binary.LittleEndian.Uint32(record), binary.LittleEndian.Uint32(record[4:])
+}
+
+func (db *DB) ip(i uint32) net.IP {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, db.ipKey(i))
+	return ip
+}
+
+func (db *DB) nameRecord(i uint32) (offset, length, first, count uint32) {
+	record := db.names[i*nameSize:]
+	return binary.LittleEndian.Uint32(record), binary.LittleEndian.Uint32(record[4:]),
+		binary.LittleEndian.Uint32(record[8:]), binary.LittleEndian.Uint32(record[12:])
+}
+
+// name returns the host name with the given index, ok is false if the index or its string is out of range.
+func (db *DB) name(i uint32) (hostName string, ok bool) {
+	name, ok := db.nameBytes(i)
+	return string(name), ok
+}
+
+func (db *DB) nameBytes(i uint32) ([]byte, bool) {
+	if i >= db.header.nameCount {
+		return nil, false
+	}
+	offset, length, _, _ := db.nameRecord(i)
+	if !validRange(offset, length, uint32(len(db.strings))) {
+		return nil, false
+	}
+	return db.strings[offset : offset+length], true
+}
+
+// validRange reports whether count items from first are within a section of size items.
+func validRange(first, count, size uint32) bool {
+	return uint64(first)+uint64(count) <= uint64(size)
+}
+
+// hashName returns the FNV-1a hash of the given host name.
+func hashName(hostName string) uint32 {
+	const prime = 16777619
+	hash := uint32(2166136261)
+	for i := 0; i < len(hostName); i++ {
+		hash = (hash ^ uint32(hostName[i])) * prime
+	}
+	return hash
+}
@@ -0,0 +1,269 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"hash/crc32"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testEntrySet() hosts.EntrySet {
+	entrySet := hosts.NewEntrySet()
+	entrySet.AddEntry(
+		hosts.NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}),
+		hosts.NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost"}),
+		hosts.NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "loopback"}),
+		hosts.NewEntryUnsafe(net.ParseIP("0.0.0.0"), []string{"ads.example.com", "Tracker.example.com"}))
+	return entrySet
+}
+
+func compile(entrySet hosts.EntrySet, t *testing.T) []byte {
+	buffer := &bytes.Buffer{}
+	if err := Compile(entrySet, buffer); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hostsdb")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "hosts.db")
+	if err := WriteFile(testEntrySet(), path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer db.Close()
+
+	if db.Version() != Version || db.Len() != 6 {
+		t.Errorf("expected version %d with 6 host names, actual %d with %d", Version, db.Version(), db.Len())
+	}
+
+	lookups := []struct {
+		hostName string
+		ips      string
+	}{
+		{hostName: "LocalHost", ips: "[127.0.0.1 ::1]"},
+		{hostName: "api.local", ips: "[10.0.0.1]"},
+		{hostName: "tracker.example.com", ips: "[0.0.0.0]"},
+		{hostName: "example.com", ips: "[]"},
+	}
+	for _, lookup := range lookups {
+		t.Run(lookup.hostName, func(t *testing.T) {
+			ips, ok := db.LookupHost(lookup.hostName)
+			if fmt.Sprint(ips) != lookup.ips || ok != (lookup.ips != "[]") {
+				t.Errorf("expected ips %s, actual %v (found %v)", lookup.ips, ips, ok)
+			}
+		})
+	}
+
+	hostNames, ok := db.EntriesOfIP(net.ParseIP("10.0.0.1"))
+	if !ok || !reflect.DeepEqual(hostNames, []string{"api.local", "web.local"}) {
+		t.Errorf("unexpected host names %v of 10.0.0.1", hostNames)
+	}
+	if hostNames, ok := db.EntriesOfIP(net.ParseIP("10.0.0.2")); ok {
+		t.Errorf("expected no host names of 10.0.0.2, actual %v", hostNames)
+	}
+}
+
+func TestCompile_LikeEntrySet(t *testing.T) {
+	content, err := ioutil.ReadFile("../parser/testdata/blocklist.hosts")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	entrySet, err := parser.Read(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	db, err := New(compile(entrySet, t))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	for _, entry := range entrySet.AllEntries() {
+		expected, _ := entrySet.EntriesOfIP(entry.Ip())
+		if actual, ok := db.EntriesOfIP(entry.Ip()); !ok || !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected %d host names of %s, actual %d", len(expected), entry.Ip(), len(actual))
+		}
+		for _, hostName := range entry.HostNames() {
+			expected, _ := entrySet.LookupHost(hostName)
+			if actual, ok := db.LookupHost(hostName); !ok || fmt.Sprint(actual) != fmt.Sprint(expected) {
+				t.Errorf("expected ips %v of %s, actual %v", expected, hostName, actual)
+			}
+		}
+	}
+}
+
+func TestCompile_Empty(t *testing.T) {
+	db, err := New(compile(hosts.NewEntrySet(), t))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := db.LookupHost("localhost"); ok || db.Len() != 0 {
+		t.Errorf("expected an empty database")
+	}
+}
+
+// rechecksum returns the given database with a valid checksum.
+func rechecksum(data []byte) []byte {
+	binary.LittleEndian.PutUint32(data[12:], crc32.Checksum(data[headerSize:], castagnoli))
+	return data
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(data []byte) []byte
+		// verify is set if the damage is only found by Verify
+		verify   bool
+		expected error
+	}{
+		{name: "empty", modify: func(data []byte) []byte { return nil }, expected: ErrorInvalidFormat},
+		{name: "magic", modify: func(data []byte) []byte { data[0] = 'X'; return data }, expected: ErrorInvalidFormat},
+		{name: "version", modify: func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[8:], Version+1)
+			return data
+		}, expected: ErrorUnsupportedVersion},
+		{name: "checksum", modify: func(data []byte) []byte { data[len(data)-1] ^= 1; return data },
+			verify: true, expected: ErrorChecksum},
+		{name: "truncated", modify: func(data []byte) []byte { return rechecksum(data[:len(data)-4]) },
+			verify: true, expected: ErrorInvalidFormat},
+		{name: "offset", modify: func(data []byte) []byte {
+			binary.LittleEndian.PutUint64(data[32:], uint64(len(data)))
+			return data
+		}, expected: ErrorInvalidFormat},
+		{name: "name index", modify: func(data []byte) []byte {
+			ipNames := binary.LittleEndian.Uint64(data[40:])
+			binary.LittleEndian.PutUint32(data[ipNames:], 1000)
+			return rechecksum(data)
+		}, verify: true, expected: ErrorInvalidFormat},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := test.modify(compile(testEntrySet(), t))
+			db, err := New(data)
+			if !test.verify {
+				if !errors.Is(err, test.expected) {
+					t.Errorf("expected error %v, actual %v", test.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			// queries of a damaged database do not read beyond its data
+			for _, hostName := range []string{"localhost", "api.local", "web.local", "tracker.example.com"} {
+				db.LookupHost(hostName)
+			}
+			for _, ip := range []string{"10.0.0.1", "127.0.0.1", "::1", "0.0.0.0"} {
+				db.EntriesOfIP(net.ParseIP(ip))
+			}
+			if err := db.Verify(); !errors.Is(err, test.expected) {
+				t.Errorf("expected error %v, actual %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	db, err := New(compile(testEntrySet(), t))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := db.Verify(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLookupHost_FullSlots(t *testing.T) {
+	data := compile(testEntrySet(), t)
+	// every slot refers to the first host name, so a probe finds no empty slot
+	slots := binary.LittleEndian.Uint64(data[64:])
+	slotCount := binary.LittleEndian.Uint32(data[24:])
+	for i := uint32(0); i < slotCount; i++ {
+		binary.LittleEndian.PutUint32(data[slots+4*uint64(i):], 1)
+	}
+	db, err := New(data)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if ips, ok := db.LookupHost("example.com"); ok {
+		t.Errorf("expected example.com not to be found, actual %v", ips)
+	}
+}
+
+func BenchmarkLookupHost(b *testing.B) {
+	content, err := ioutil.ReadFile("../parser/testdata/blocklist.hosts")
+	if err != nil {
+		b.Fatalf("unexpected error %v", err)
+	}
+	entrySet, err := parser.Read(bytes.NewReader(content))
+	if err != nil {
+		b.Fatalf("unexpected error %v", err)
+	}
+	data := &bytes.Buffer{}
+	if err := Compile(entrySet, data); err != nil {
+		b.Fatalf("unexpected error %v", err)
+	}
+
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			entrySet, _ := parser.Read(bytes.NewReader(content))
+			entrySet.LookupHost("localhost")
+		}
+	})
+	b.Run("open", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			db, _ := New(data.Bytes())
+			db.LookupHost("localhost")
+		}
+	})
+	db, _ := New(data.Bytes())
+	b.Run("lookup", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			db.LookupHost("localhost")
+		}
+	})
+}
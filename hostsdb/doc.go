@@ -0,0 +1,47 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+/*
+Package hostsdb compiles entry sets into a read-only binary lookup database, which is mapped into memory
+and queried without parsing, e.g. by a DNS sidecar which would otherwise read a huge hosts file on every start.
+
+A database file starts with a header of 80 bytes, all numbers are little-endian:
+
+  magic      [8]byte  "HOSTSDB\x00"
+  version    uint32   Version
+  checksum   uint32   CRC-32 (Castagnoli) of everything behind the header
+  ipCount    uint32   number of ip records
+  nameCount  uint32   number of name records
+  slotCount  uint32   number of hash slots, a power of two
+  refCount   uint32   number of (ip, name) pairs
+  offsets    6*uint64 offsets of the sections ips, ipNames, names, nameIPs, slots and strings
+
+The sections are
+
+  ips      ipCount records of ip [16]byte, first uint32, count uint32, ordered by ip
+  ipNames  refCount uint32 name indexes, the names of an ip are ipNames[first:first+count] ordered by name
+  names    nameCount records of offset uint32, length uint32, first uint32, count uint32
+  nameIPs  refCount uint32 ip indexes, the ips of a name are nameIPs[first:first+count] ordered by hosts.CompareIPs
+  slots    slotCount uint32 name indexes plus one of an open addressing hash table (FNV-1a, linear probing), 0 if empty
+  strings  the host names, a name is strings[offset:offset+length]
+*/
+package hostsdb
@@ -0,0 +1,258 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bitofcode/hosts/internal/diff"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuditMaxSize is the size in bytes at which an audit log is rotated.
+	DefaultAuditMaxSize = 10 << 20
+	// DefaultAuditMaxFiles is the number of rotated audit logs which are kept.
+	DefaultAuditMaxFiles = 5
+)
+
+// An AuditChange is one line added to or removed from a file.
+type AuditChange struct {
+	// Op is "+" for an added and "-" for a removed line.
+	Op string `json:"op"`
+	// Line is the line number in the new file for added lines and in the old file for removed lines.
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// An AuditRecord describes who changed a file, when and what.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user"`
+	Uid  int       `json:"uid"`
+	// SudoUser is the user who invoked sudo, if the change was made through sudo.
+	SudoUser    string        `json:"sudo_user,omitempty"`
+	Process     string        `json:"process"`
+	Pid         int           `json:"pid"`
+	CommandLine []string      `json:"command_line"`
+	Path        string        `json:"path"`
+	Changes     []AuditChange `json:"changes"`
+}
+
+// An AuditLog is a WriteHook which appends an AuditRecord as one JSON line to the file at Path
+// for every write which changes a file. The log is rotated to Path.1, Path.2, ... when it grows beyond MaxSize.
+type AuditLog struct {
+	Path string
+	// MaxSize defaults to DefaultAuditMaxSize.
+	MaxSize int64
+	// MaxFiles defaults to DefaultAuditMaxFiles.
+	MaxFiles int
+	// Clock defaults to SystemClock.
+	Clock Clock
+
+	mutex sync.Mutex
+}
+
+// BeforeWrite checks that the log can be appended, so a change which cannot be recorded is not written.
+// The change itself is recorded after it was written.
+func (a *AuditLog) BeforeWrite(path string, old, new []byte) error {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	file, err := a.open()
+	if err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// AfterWrite appends a record of the change, unless the content is unchanged.
+func (a *AuditLog) AfterWrite(path string, old, new []byte) error {
+	changes := auditChanges(old, new)
+	if len(changes) == 0 {
+		return nil
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	record := AuditRecord{
+		Time:        a.clock().Now().UTC(),
+		Uid:         os.Getuid(),
+		SudoUser:    os.Getenv("SUDO_USER"),
+		Process:     filepath.Base(os.Args[0]),
+		Pid:         os.Getpid(),
+		CommandLine: os.Args,
+		Path:        path,
+		Changes:     changes,
+	}
+	if current, err := user.Current(); err == nil {
+		record.User = current.Username
+	}
+	return a.Append(record)
+}
+
+func auditChanges(old, new []byte) []AuditChange {
+	changes := make([]AuditChange, 0)
+	for _, edit := range diff.Lines(diff.SplitLines(old), diff.SplitLines(new)) {
+		switch edit.Op {
+		case diff.Delete:
+			changes = append(changes, AuditChange{Op: "-", Line: edit.OldLine, Text: edit.Text})
+		case diff.Insert:
+			changes = append(changes, AuditChange{Op: "+", Line: edit.NewLine, Text: edit.Text})
+		}
+	}
+	return changes
+}
+
+// Append appends the record to the log, the log is rotated first if the record does not fit anymore.
+func (a *AuditLog) Append(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if info, err := os.Stat(a.Path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > a.maxSize() {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+
+	file, err := a.open()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// open opens the log for appending, the log and its directory are created if they do not exist.
+func (a *AuditLog) open() (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(a.Path), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(a.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+}
+
+// rotate renames Path.n to Path.n+1 and Path to Path.1, the oldest log is dropped.
+func (a *AuditLog) rotate() error {
+	for i := a.maxFiles() - 1; i >= 1; i-- {
+		err := os.Rename(a.rotated(i), a.rotated(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(a.Path, a.rotated(1))
+}
+
+func (a *AuditLog) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", a.Path, n)
+}
+
+// Records returns all records of the log including the rotated logs, the oldest first.
+func (a *AuditLog) Records() ([]AuditRecord, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	records := make([]AuditRecord, 0)
+	for i := a.maxFiles(); i >= 0; i-- {
+		path := a.Path
+		if i > 0 {
+			path = a.rotated(i)
+		}
+
+		read, err := readAuditRecords(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		records = append(records, read...)
+	}
+	return records, nil
+}
+
+func readAuditRecords(path string) ([]AuditRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	records := make([]AuditRecord, 0)
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record AuditRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+			}
+			records = append(records, record)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+func (a *AuditLog) maxSize() int64 {
+	if a.MaxSize <= 0 {
+		return DefaultAuditMaxSize
+	}
+	return a.MaxSize
+}
+
+func (a *AuditLog) maxFiles() int {
+	if a.MaxFiles <= 0 {
+		return DefaultAuditMaxFiles
+	}
+	return a.MaxFiles
+}
+
+func (a *AuditLog) clock() Clock {
+	if a.Clock == nil {
+		return SystemClock
+	}
+	return a.Clock
+}
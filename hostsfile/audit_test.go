@@ -0,0 +1,178 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"errors"
+	"github.com/bitofcode/hosts"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type rejectingHook struct{}
+
+func (rejectingHook) BeforeWrite(path string, old, new []byte) error {
+	return errors.New("rejected")
+}
+
+func (rejectingHook) AfterWrite(path string, old, new []byte) error {
+	return nil
+}
+
+func TestRegisterWriteHook(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	doc, err := ReadDocument(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := doc.Add(hosts.NewEntryUnsafe(net.ParseIP("10.1.2.3"), []string{"api.prod"})); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	unregister := RegisterWriteHook(rejectingHook{})
+	if err := WriteDocument(doc, path); err == nil || err.Error() != "rejected" {
+		t.Errorf("expected the write to be rejected, actual %v", err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n", t)
+
+	unregister()
+	if err := WriteDocument(doc, path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n10.1.2.3  api.prod\n", t)
+}
+
+type failingAfterHook struct{}
+
+func (failingAfterHook) BeforeWrite(path string, old, new []byte) error {
+	return nil
+}
+
+func (failingAfterHook) AfterWrite(path string, old, new []byte) error {
+	return errors.New("not recorded")
+}
+
+func TestRegisterWriteHook_AfterWriteError(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	unregister := RegisterWriteHook(failingAfterHook{})
+	defer unregister()
+
+	// the file is already replaced when AfterWrite fails, the caller learns about both
+	err := writeFile(path, []byte("10.1.2.3 api.prod\n"))
+	var afterWrite *AfterWriteError
+	if !errors.As(err, &afterWrite) || afterWrite.Path != path || afterWrite.Err.Error() != "not recorded" {
+		t.Fatalf("expected an *AfterWriteError, actual %v", err)
+	}
+	assertFileContent(path, "10.1.2.3 api.prod\n", t)
+}
+
+func TestAuditLog_NotWritable(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	// the directory of the log is a file, so the log cannot be created
+	unregister := RegisterWriteHook(&AuditLog{Path: filepath.Join(path, "audit.log")})
+	defer unregister()
+
+	if err := writeFile(path, []byte("10.1.2.3 api.prod\n")); err == nil {
+		t.Fatalf("expected the write to fail without audit log")
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n", t)
+}
+
+func TestAuditLog(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n10.1.2.3 api.prod\n", t)
+	defer cleanup()
+
+	audit := &AuditLog{
+		Path:  filepath.Join(filepath.Dir(path), "log", "audit.log"),
+		Clock: &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	unregister := RegisterWriteHook(audit)
+	defer unregister()
+
+	writes := []string{
+		"127.0.0.1 localhost\n10.1.2.4 api.prod\n",
+		"127.0.0.1 localhost\n10.1.2.4 api.prod\n",
+		"127.0.0.1 localhost\n",
+		"127.0.0.1 localhost\n10.1.2.5 web.prod\n",
+		"127.0.0.1 localhost\n10.1.2.6 web.prod\n",
+	}
+	for _, content := range writes {
+		if err := writeFile(path, []byte(content)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	records, err := audit.Records()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the unchanged write is not recorded
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, actual %d", len(records))
+	}
+
+	last := records[len(records)-1]
+	if last.Path != path || last.Pid == 0 || len(last.CommandLine) == 0 || !last.Time.Equal(audit.Clock.Now()) {
+		t.Errorf("unexpected record %+v", last)
+	}
+	changes := make([]string, len(last.Changes))
+	for i, change := range last.Changes {
+		changes[i] = change.Op + change.Text
+	}
+	if actual := strings.Join(changes, "|"); actual != "-10.1.2.5 web.prod|+10.1.2.6 web.prod" {
+		t.Errorf("unexpected changes '%s'", actual)
+	}
+}
+
+func TestAuditLog_Rotate(t *testing.T) {
+	path, cleanup := createHostsFile("", t)
+	defer cleanup()
+
+	audit := &AuditLog{Path: path + ".log", MaxSize: 100, MaxFiles: 2}
+	for pid := 1; pid <= 5; pid++ {
+		if err := audit.Append(AuditRecord{Pid: pid, Path: path}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	records, err := audit.Records()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	pids := make([]int, len(records))
+	for i, record := range records {
+		pids[i] = record.Pid
+	}
+	if len(pids) != 3 || pids[0] != 3 || pids[1] != 4 || pids[2] != 5 {
+		t.Errorf("expected the records of the pids [3 4 5], actual %v", pids)
+	}
+}
@@ -0,0 +1,238 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat is the time format of backup ids, it sorts in chronological order.
+const backupTimeFormat = "20060102T150405Z"
+
+var ErrorBackupNotFound = errors.New("backup not found")
+
+// A Backup is a copy of a file taken before it was overwritten.
+type Backup struct {
+	// ID is the time of the backup followed by the beginning of the content hash.
+	ID string
+	// Path is the absolute path of the file which was backed up.
+	Path string
+	Time time.Time
+	// Hash is the beginning of the hex encoded SHA-256 hash of the content.
+	Hash string
+	Size int64
+}
+
+// A BackupStore is a WriteHook which saves the current content of a file before it is overwritten, the backups
+// of every file are kept in their own subdirectory of Dir. A content which is already backed up is not saved again,
+// its backup is renewed instead.
+type BackupStore struct {
+	Dir string
+	// MaxCount is the number of backups which are kept, 0 for no limit.
+	MaxCount int
+	// MaxAge is the time a backup is kept, 0 for no limit.
+	MaxAge time.Duration
+	// Clock defaults to SystemClock.
+	Clock Clock
+}
+
+// BeforeWrite saves the current content of the file, nothing is saved if the file does not exist yet.
+func (s *BackupStore) BeforeWrite(path string, old, new []byte) error {
+	if old == nil || bytes.Equal(old, new) {
+		return nil
+	}
+	_, err := s.Save(path, old)
+	return err
+}
+
+// AfterWrite does nothing.
+func (s *BackupStore) AfterWrite(path string, old, new []byte) error {
+	return nil
+}
+
+// Save stores the content of the file at path as a new backup and applies the retention policy.
+// If a backup with the same content exists already, it gets the current time, so it is not pruned before the
+// backups of older contents.
+func (s *BackupStore) Save(path string, content []byte) (Backup, error) {
+	hash := contentHash(content)[:12]
+
+	backups, err := s.List(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	dir := s.dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Backup{}, err
+	}
+	now := s.clock().Now().UTC().Truncate(time.Second)
+	backup := Backup{
+		ID:   now.Format(backupTimeFormat) + "-" + hash,
+		Path: absolutePath(path),
+		Time: now,
+		Hash: hash,
+		Size: int64(len(content)),
+	}
+
+	renewed := false
+	for _, existing := range backups {
+		if existing.Hash != hash {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, existing.ID), filepath.Join(dir, backup.ID)); err != nil {
+			return Backup{}, err
+		}
+		renewed = true
+		break
+	}
+	if !renewed {
+		if err := ioutil.WriteFile(filepath.Join(dir, backup.ID), content, 0600); err != nil {
+			return Backup{}, err
+		}
+	}
+	// the modification time orders backups of the same second
+	modified := time.Now()
+	if err := os.Chtimes(filepath.Join(dir, backup.ID), modified, modified); err != nil {
+		return Backup{}, err
+	}
+	return backup, s.Prune(path)
+}
+
+// List returns all backups of the file at path, the oldest first.
+func (s *BackupStore) List(path string) ([]Backup, error) {
+	infos, err := ioutil.ReadDir(s.dir(path))
+	if os.IsNotExist(err) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]Backup, 0, len(infos))
+	modified := make(map[string]time.Time)
+	for _, info := range infos {
+		parts := strings.Split(info.Name(), "-")
+		if info.IsDir() || len(parts) != 2 {
+			continue
+		}
+		backupTime, err := time.Parse(backupTimeFormat, parts[0])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			ID:   info.Name(),
+			Path: absolutePath(path),
+			Time: backupTime,
+			Hash: parts[1],
+			Size: info.Size(),
+		})
+		modified[info.Name()] = info.ModTime()
+	}
+	// backups of the same second are ordered by the time they were written
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.Before(backups[j].Time)
+		}
+		return modified[backups[i].ID].Before(modified[backups[j].ID])
+	})
+	return backups, nil
+}
+
+// Get returns the backup of the file at path with the given id, or the only one whose hash starts with id,
+// together with its content.
+func (s *BackupStore) Get(path string, id string) (Backup, []byte, error) {
+	backups, err := s.List(path)
+	if err != nil {
+		return Backup{}, nil, err
+	}
+
+	matches := make([]Backup, 0)
+	for _, backup := range backups {
+		if backup.ID == id {
+			matches = []Backup{backup}
+			break
+		}
+		if id != "" && strings.HasPrefix(backup.Hash, id) {
+			matches = append(matches, backup)
+		}
+	}
+	if len(matches) != 1 {
+		return Backup{}, nil, ErrorBackupNotFound
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(s.dir(path), matches[0].ID))
+	if err != nil {
+		return Backup{}, nil, err
+	}
+	return matches[0], content, nil
+}
+
+// Restore atomically replaces the file at path with the content of its backup with the given id.
+// The restore is a write like any other, so the replaced content is backed up as well.
+func (s *BackupStore) Restore(id string, path string) error {
+	_, content, err := s.Get(path, id)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, content)
+}
+
+// Prune removes all backups of the file at path exceeding MaxCount or MaxAge.
+func (s *BackupStore) Prune(path string) error {
+	backups, err := s.List(path)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock().Now()
+	for i, backup := range backups {
+		tooMany := s.MaxCount > 0 && len(backups)-i > s.MaxCount
+		tooOld := s.MaxAge > 0 && now.Sub(backup.Time) > s.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir(path), backup.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dir returns the directory of the backups of the file at path, named after its escaped absolute path.
+func (s *BackupStore) dir(path string) string {
+	return filepath.Join(s.Dir, url.PathEscape(absolutePath(path)))
+}
+
+func (s *BackupStore) clock() Clock {
+	if s.Clock == nil {
+		return SystemClock
+	}
+	return s.Clock
+}
@@ -0,0 +1,169 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupStore(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := &BackupStore{Dir: filepath.Join(filepath.Dir(path), "backups"), MaxCount: 2, Clock: clock}
+	unregister := RegisterWriteHook(store)
+	defer unregister()
+
+	writes := []string{
+		"127.0.0.1 localhost\n10.1.2.3 api.prod\n",
+		"127.0.0.1 localhost\n",
+		"127.0.0.1 localhost\n10.1.2.3 api.prod\n",
+		"127.0.0.1 localhost\n10.1.2.4 web.prod\n",
+		"127.0.0.1 localhost\n10.1.2.5 db.prod\n",
+	}
+	for _, content := range writes {
+		clock.After(time.Minute)
+		if err := writeFile(path, []byte(content)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	// the duplicates of the first two backups renewed them, the third backup removed the first one
+	backups, err := store.List(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, actual %v", backups)
+	}
+	if backups[0].ID != "20200501T120400Z-"+backups[0].Hash || !backups[1].Time.Equal(clock.Now()) || backups[1].Size != 38 {
+		t.Errorf("unexpected backups %v", backups)
+	}
+
+	if err := store.Restore(backups[0].Hash[:6], path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n10.1.2.3 api.prod\n", t)
+
+	if err := store.Restore("unknown", path); err != ErrorBackupNotFound {
+		t.Errorf("expected %v, actual %v", ErrorBackupNotFound, err)
+	}
+}
+
+func TestBackupStore_PruneByAge(t *testing.T) {
+	path, cleanup := createHostsFile("", t)
+	defer cleanup()
+
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := &BackupStore{Dir: filepath.Join(filepath.Dir(path), "backups"), MaxAge: time.Hour, Clock: clock}
+	for _, content := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := store.Save(path, []byte(content)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		clock.After(40 * time.Minute)
+	}
+
+	if err := store.Prune(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	backups, err := store.List(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(backups) != 1 || backups[0].Size != 2 {
+		t.Errorf("expected only the last backup, actual %v", backups)
+	}
+}
+
+func TestBackupStore_RenewDuplicate(t *testing.T) {
+	path, cleanup := createHostsFile("", t)
+	defer cleanup()
+
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := &BackupStore{Dir: filepath.Join(filepath.Dir(path), "backups"), MaxAge: time.Hour, Clock: clock}
+	for _, content := range []string{"a\n", "b\n", "a\n"} {
+		if _, err := store.Save(path, []byte(content)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		clock.After(40 * time.Minute)
+	}
+
+	// the content a was saved again 40 minutes ago, its backup is not pruned by age
+	if err := store.Prune(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	backups, err := store.List(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(backups) != 1 || backups[0].ID != "20200501T132000Z-"+backups[0].Hash {
+		t.Fatalf("expected the renewed backup, actual %v", backups)
+	}
+	if _, content, err := store.Get(path, backups[0].ID); err != nil || string(content) != "a\n" {
+		t.Errorf("unexpected content '%s', error %v", content, err)
+	}
+}
+
+func TestBackupStore_PerFile(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+	fragment := filepath.Join(filepath.Dir(path), "fragment.hosts")
+
+	store := &BackupStore{Dir: filepath.Join(filepath.Dir(path), "backups")}
+	unregister := RegisterWriteHook(store)
+	defer unregister()
+
+	if err := writeFile(fragment, []byte("10.1.2.3 api.prod\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := writeFile(fragment, []byte("10.1.2.4 api.prod\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := writeFile(path, []byte("127.0.0.1 localhost\n10.1.2.5 web.prod\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	backups, err := store.List(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(backups) != 1 || backups[0].Path != path || backups[0].Size != 20 {
+		t.Fatalf("expected only the backup of the file, actual %v", backups)
+	}
+	fragmentBackups, err := store.List(fragment)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(fragmentBackups) != 1 || fragmentBackups[0].Path != fragment {
+		t.Fatalf("expected only the backup of the fragment, actual %v", fragmentBackups)
+	}
+
+	// a backup of another file is not found for the file
+	if err := store.Restore(fragmentBackups[0].ID, path); err != ErrorBackupNotFound {
+		t.Errorf("expected %v, actual %v", ErrorBackupNotFound, err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n10.1.2.5 web.prod\n", t)
+}
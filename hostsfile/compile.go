@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FragmentPattern is the file pattern of the fragments in a drop-in directory.
+const FragmentPattern = "*.hosts"
+
+// FragmentConflictError is returned when a fragment maps a host name to another ip
+// than the base file or a previous fragment.
+type FragmentConflictError struct {
+	Fragment  string
+	Conflicts []hosts.Conflict
+}
+
+func (e *FragmentConflictError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Fragment, &hosts.ConflictError{Conflicts: e.Conflicts})
+}
+
+// A Compiler produces a hosts file from a base file and all fragments of a drop-in directory
+// (e.g. /etc/hosts.d/*.hosts) in lexical order.
+type Compiler struct {
+	Base string
+	Dir  string
+}
+
+// Fragments returns the paths of all fragments in lexical order.
+func (c *Compiler) Fragments() ([]string, error) {
+	return filepath.Glob(filepath.Join(c.Dir, FragmentPattern))
+}
+
+// Compile returns the content of the base file followed by the lines of each fragment (including comments
+// and disabled entries), preceded by a comment naming the fragment. A *FragmentConflictError is returned if a fragment
+// maps a host name to another ip than the base file or a previous fragment.
+func (c *Compiler) Compile() ([]byte, error) {
+	base, err := ioutil.ReadFile(c.Base)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := parser.ReadWithOrigin(bytes.NewReader(base), origin(c.Base))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", c.Base, err)
+	}
+
+	fragments, err := c.Fragments()
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.NewBuffer(base)
+	if len(base) > 0 && base[len(base)-1] != '\n' {
+		buffer.WriteString("\n")
+	}
+	for _, fragment := range fragments {
+		doc, err := ReadDocument(fragment)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fragment, err)
+		}
+		if conflicts := hosts.Merge(merged, doc.EntrySet()); len(conflicts) > 0 {
+			return nil, &FragmentConflictError{Fragment: fragment, Conflicts: conflicts}
+		}
+
+		fmt.Fprintf(buffer, "# --- %s ---\n", fragment)
+		if _, err := doc.WriteTo(buffer); err != nil {
+			return nil, fmt.Errorf("%s: %v", fragment, err)
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// CompileTo compiles the hosts file and writes it to the target path,
+// the target is only rewritten if its content changes.
+func (c *Compiler) CompileTo(target string) (changed bool, err error) {
+	content, err := c.Compile()
+	if err != nil {
+		return false, err
+	}
+
+	current, err := ioutil.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+	return true, writeFile(target, content)
+}
@@ -0,0 +1,125 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestCompiler(fragments map[string]string, t *testing.T) (compiler *Compiler, cleanup func()) {
+	dir, err := ioutil.TempDir("", "compile")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	base := filepath.Join(dir, "hosts.base")
+	if err := ioutil.WriteFile(base, []byte("# base\n127.0.0.1 localhost"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dropIn := filepath.Join(dir, "hosts.d")
+	if err := os.Mkdir(dropIn, 0755); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for name, content := range fragments {
+		if err := ioutil.WriteFile(filepath.Join(dropIn, name), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	return &Compiler{Base: base, Dir: dropIn}, func() { os.RemoveAll(dir) }
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	compiler, cleanup := newTestCompiler(map[string]string{
+		"20-vpn.hosts":    "10.8.0.1 vpn.internal\n",
+		"10-docker.hosts": "# docker\n172.17.0.2 db.docker\n# 172.17.0.3 web.docker\n",
+		"ignored.txt":     "10.0.0.1 ignored.local\n",
+	}, t)
+	defer cleanup()
+
+	content, err := compiler.Compile()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	expected := "# base\n127.0.0.1 localhost\n" +
+		"# --- " + filepath.Join(compiler.Dir, "10-docker.hosts") + " ---\n" +
+		"# docker\n172.17.0.2 db.docker\n# 172.17.0.3 web.docker\n" +
+		"# --- " + filepath.Join(compiler.Dir, "20-vpn.hosts") + " ---\n" +
+		"10.8.0.1 vpn.internal\n"
+	if string(content) != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, string(content))
+	}
+}
+
+func TestCompiler_CompileConflict(t *testing.T) {
+	compiler, cleanup := newTestCompiler(map[string]string{
+		"10-docker.hosts": "172.17.0.2 db.local\n",
+		"20-vpn.hosts":    "10.8.0.1 db.local\n",
+	}, t)
+	defer cleanup()
+
+	_, err := compiler.Compile()
+	conflictError, ok := err.(*FragmentConflictError)
+	if !ok {
+		t.Fatalf("expected a *FragmentConflictError, actual '%v'", err)
+	}
+	if conflictError.Fragment != filepath.Join(compiler.Dir, "20-vpn.hosts") ||
+		len(conflictError.Conflicts) != 1 || conflictError.Conflicts[0].HostName != "db.local" {
+		t.Errorf("unexpected conflict %v", conflictError)
+	}
+	if !strings.Contains(err.Error(), filepath.Join(compiler.Dir, "10-docker.hosts")+":1") {
+		t.Errorf("expected origin of the conflicting mapping in '%v'", err)
+	}
+}
+
+func TestCompiler_CompileConflictWithBase(t *testing.T) {
+	compiler, cleanup := newTestCompiler(map[string]string{"10-docker.hosts": "172.17.0.2 localhost\n"}, t)
+	defer cleanup()
+
+	_, err := compiler.Compile()
+	if _, ok := err.(*FragmentConflictError); !ok {
+		t.Fatalf("expected a *FragmentConflictError, actual '%v'", err)
+	}
+	if !strings.Contains(err.Error(), compiler.Base+":2") {
+		t.Errorf("expected origin of the base mapping in '%v'", err)
+	}
+}
+
+func TestCompiler_CompileTo(t *testing.T) {
+	compiler, cleanup := newTestCompiler(map[string]string{"10-vpn.hosts": "10.8.0.1 vpn.internal\n"}, t)
+	defer cleanup()
+
+	target := filepath.Join(filepath.Dir(compiler.Dir), "hosts")
+	changed, err := compiler.CompileTo(target)
+	if err != nil || !changed {
+		t.Fatalf("expected target to be written, changed=%v error=%v", changed, err)
+	}
+
+	changed, err = compiler.CompileTo(target)
+	if err != nil || changed {
+		t.Errorf("expected target to be unchanged, changed=%v error=%v", changed, err)
+	}
+}
@@ -0,0 +1,214 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// A WriteHook is notified about every file written by this package, e.g. to record or back up changes.
+type WriteHook interface {
+	// BeforeWrite is called with the current and the new content before the file at path is written,
+	// old is nil if the file does not exist. An error aborts the write.
+	BeforeWrite(path string, old, new []byte) error
+	// AfterWrite is called after the file at path was written. As the file is already changed, an error is
+	// returned by the write as *AfterWriteError. Hooks which must not miss a change check in BeforeWrite that
+	// AfterWrite can succeed.
+	AfterWrite(path string, old, new []byte) error
+}
+
+// AfterWriteError is returned by a write if the file was written, but an AfterWrite hook failed.
+type AfterWriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *AfterWriteError) Error() string {
+	return fmt.Sprintf("%s was written, but %v", e.Path, e.Err)
+}
+
+func (e *AfterWriteError) Unwrap() error {
+	return e.Err
+}
+
+type registeredHook struct {
+	id   int
+	hook WriteHook
+}
+
+var (
+	hooksMutex sync.Mutex
+	hooks      []registeredHook
+	nextHookId int
+)
+
+// RegisterWriteHook registers the given hook for all following writes and returns a function which removes it again.
+// Hooks are called in the order they were registered.
+func RegisterWriteHook(hook WriteHook) (unregister func()) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	id := nextHookId
+	nextHookId++
+	hooks = append(hooks, registeredHook{id: id, hook: hook})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { unregisterWriteHook(id) })
+	}
+}
+
+func unregisterWriteHook(id int) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	for i, h := range hooks {
+		if h.id == id {
+			hooks = append(hooks[:i:i], hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func writeHooks() []WriteHook {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	registered := make([]WriteHook, len(hooks))
+	for i, h := range hooks {
+		registered[i] = h.hook
+	}
+	return registered
+}
+
+// writeFile atomically replaces the content of the file at path, all write hooks are called around the write.
+func writeFile(path string, content []byte) error {
+	return writeFileIf(path, content, nil)
+}
+
+// writeFileIf is writeFile, but the write is aborted with the error of check for the current content,
+// unless check is nil. old is nil if the file does not exist.
+func writeFileIf(path string, content []byte, check func(old []byte) error) error {
+	old, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if check != nil {
+		if err := check(old); err != nil {
+			return err
+		}
+	}
+
+	registered := writeHooks()
+	for _, hook := range registered {
+		if err := hook.BeforeWrite(path, old, content); err != nil {
+			return err
+		}
+	}
+	if err := replaceFile(path, content); err != nil {
+		return err
+	}
+	// all hooks are called although one failed, the first error is returned
+	var afterErr error
+	for _, hook := range registered {
+		if err := hook.AfterWrite(path, old, content); err != nil && afterErr == nil {
+			afterErr = &AfterWriteError{Path: path, Err: err}
+		}
+	}
+	return afterErr
+}
+
+// replaceFile atomically replaces the file at path by writing a temporary file in the same directory and renaming it,
+// the permissions and the owner of an existing file are kept. A symbolic link is followed, so the file it points to
+// is replaced and the link is kept. If the file cannot be renamed over, e.g. a bind mount into a container, it is
+// overwritten in place instead, which is not atomic.
+func replaceFile(path string, content []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	info, err := os.Stat(path)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), mode); err != nil {
+		return err
+	}
+	if info != nil {
+		if err := chownLike(file.Name(), info); err != nil {
+			return err
+		}
+	}
+
+	err = os.Rename(file.Name(), path)
+	if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EXDEV) {
+		return overwriteFile(path, content)
+	}
+	return err
+}
+
+// overwriteFile truncates the file at path and writes the content, the file keeps its inode.
+func overwriteFile(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
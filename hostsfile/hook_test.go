@@ -0,0 +1,93 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceFile_Symlink(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	link := filepath.Join(filepath.Dir(path), "link")
+	if err := os.Symlink(path, link); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+
+	if err := replaceFile(link, []byte("10.1.2.3 api.prod\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the link to be kept, actual mode %v", info.Mode())
+	}
+	assertFileContent(path, "10.1.2.3 api.prod\n", t)
+}
+
+func TestReplaceFile_KeepsMode(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := replaceFile(path, []byte("10.1.2.3 api.prod\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the mode 0600, actual %v", info.Mode().Perm())
+	}
+	assertFileContent(path, "10.1.2.3 api.prod\n", t)
+}
+
+func TestOverwriteFile(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n10.1.2.3 api.prod\n", t)
+	defer cleanup()
+
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := overwriteFile(path, []byte("127.0.0.1 localhost\n")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// a bind mounted file can only be written in place
+	if !os.SameFile(before, after) {
+		t.Errorf("expected the file to be written in place")
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n", t)
+}
@@ -23,9 +23,11 @@
 package hostsfile
 
 import (
+	"bytes"
 	"github.com/bitofcode/hosts"
 	"github.com/bitofcode/hosts/parser"
 	"os"
+	"path/filepath"
 )
 
 // Read read the content of the given path and parse it to an hosts.EntrySet.
@@ -37,17 +39,48 @@ func Read(path string) (entries hosts.EntrySet, err error) {
 
 	defer file.Close()
 
-	return parser.Read(file)
+	return parser.ReadWithOrigin(file, origin(path))
+}
+
+// origin returns the origin of the host names read from the given path.
+func origin(path string) hosts.Origin {
+	return hosts.Origin{Source: filepath.Base(path), Path: path}
+}
+
+// absolutePath returns the absolute path of path, or path itself if it cannot be resolved.
+func absolutePath(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return path
 }
 
 // Write writes the given hosts.EntrySet to the given path (create a new file if none exists).
 func Write(entries hosts.EntrySet, path string) error {
-	file, err := os.OpenFile(path, os.O_CREATE, 0666)
-	if err != nil {
+	buffer := &bytes.Buffer{}
+	if err := parser.Write(entries, buffer); err != nil {
 		return err
 	}
+	return writeFile(path, buffer.Bytes())
+}
+
+// ReadDocument read the content of the given path and parse it to a parser.Document.
+func ReadDocument(path string) (*parser.Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 
 	defer file.Close()
 
-	return parser.Write(entries, file)
+	return parser.ReadDocumentWithOrigin(file, origin(path))
+}
+
+// WriteDocument writes the given parser.Document to the given path (create a new file if none exists).
+func WriteDocument(doc *parser.Document, path string) error {
+	buffer := &bytes.Buffer{}
+	if _, err := doc.WriteTo(buffer); err != nil {
+		return err
+	}
+	return writeFile(path, buffer.Bytes())
 }
@@ -0,0 +1,231 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultJournalSize is the number of operations a Journal keeps per file.
+const DefaultJournalSize = 100
+
+var (
+	ErrorNothingToUndo = errors.New("nothing to undo")
+	ErrorNothingToRedo = errors.New("nothing to redo")
+	// ErrorJournalConflict is returned if the lines changed by an operation were changed again by someone else.
+	ErrorJournalConflict = errors.New("the lines of the operation were changed since")
+)
+
+// An Operation is one logical change of a file, e.g. "add 10.0.0.5 api.local", made by an edit of its document.
+// The changes are those recorded by the mutators of the parser.Document called by the edit.
+type Operation struct {
+	Time time.Time `json:"time"`
+	parser.Operation
+}
+
+// A Journal records the operations on files in the JSON file at Path, so the last operations can be undone
+// and redone. Only the edits made by Edit are recorded. Undo and redo revert or apply the operation on the current
+// document of the file, other changes made since are kept.
+type Journal struct {
+	Path string
+	// Size defaults to DefaultJournalSize.
+	Size int
+	// Clock defaults to SystemClock.
+	Clock Clock
+}
+
+// fileJournal holds the operations of one file, the most recent last.
+type fileJournal struct {
+	Done   []Operation `json:"done"`
+	Undone []Operation `json:"undone"`
+}
+
+// Edit reads the document at path, applies the edit, writes the document and records the operations of
+// the document mutators called by the edit as one operation with the given description.
+// An empty description is replaced by the descriptions of the mutator operations.
+func (j *Journal) Edit(path string, description string, edit func(doc *parser.Document) error) error {
+	doc, err := ReadDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+	if err := WriteDocument(doc, path); err != nil {
+		return err
+	}
+	return j.Record(path, description, doc.Operations())
+}
+
+// Record records the document operations on the file at path as one operation, all undone operations
+// of the file are dropped. Nothing is recorded if the operations do not change anything.
+func (j *Journal) Record(path string, description string, operations []parser.Operation) error {
+	changes := make([]parser.LineChange, 0)
+	descriptions := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		changes = append(changes, operation.Changes...)
+		descriptions = append(descriptions, operation.Description)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if description == "" {
+		description = strings.Join(descriptions, ", ")
+	}
+
+	_, err := j.update(path, func(journal *fileJournal) (Operation, error) {
+		operation := Operation{
+			Time:      j.clock().Now().UTC(),
+			Operation: parser.Operation{Description: description, Changes: changes},
+		}
+		journal.Done = append(journal.Done, operation)
+		if len(journal.Done) > j.size() {
+			journal.Done = journal.Done[len(journal.Done)-j.size():]
+		}
+		journal.Undone = nil
+		return operation, nil
+	})
+	return err
+}
+
+// Undo reverts the last operation on the file at path and returns it.
+func (j *Journal) Undo(path string) (Operation, error) {
+	return j.update(path, func(journal *fileJournal) (Operation, error) {
+		if len(journal.Done) == 0 {
+			return Operation{}, ErrorNothingToUndo
+		}
+		operation := journal.Done[len(journal.Done)-1]
+		if err := changeDocument(path, func(doc *parser.Document) error { return doc.Revert(operation.Operation) }); err != nil {
+			return Operation{}, err
+		}
+		journal.Done = journal.Done[:len(journal.Done)-1]
+		journal.Undone = append(journal.Undone, operation)
+		return operation, nil
+	})
+}
+
+// Redo applies the last undone operation on the file at path again and returns it.
+func (j *Journal) Redo(path string) (Operation, error) {
+	return j.update(path, func(journal *fileJournal) (Operation, error) {
+		if len(journal.Undone) == 0 {
+			return Operation{}, ErrorNothingToRedo
+		}
+		operation := journal.Undone[len(journal.Undone)-1]
+		if err := changeDocument(path, func(doc *parser.Document) error { return doc.Apply(operation.Operation) }); err != nil {
+			return Operation{}, err
+		}
+		journal.Undone = journal.Undone[:len(journal.Undone)-1]
+		journal.Done = append(journal.Done, operation)
+		return operation, nil
+	})
+}
+
+// changeDocument reverts or applies an operation on the document at path and writes it.
+func changeDocument(path string, change func(doc *parser.Document) error) error {
+	doc, err := ReadDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := change(doc); err == parser.OperationConflictError {
+		return ErrorJournalConflict
+	} else if err != nil {
+		return err
+	}
+	return WriteDocument(doc, path)
+}
+
+// Operations returns the recorded operations of the file at path which can be undone, the most recent last.
+func (j *Journal) Operations(path string) ([]Operation, error) {
+	journals, err := j.load()
+	if err != nil {
+		return nil, err
+	}
+	if journal, ok := journals[absolutePath(path)]; ok {
+		return journal.Done, nil
+	}
+	return []Operation{}, nil
+}
+
+// update applies the change to the journal of the file at path and saves the journal if it succeeds.
+func (j *Journal) update(path string, change func(journal *fileJournal) (Operation, error)) (Operation, error) {
+	journals, err := j.load()
+	if err != nil {
+		return Operation{}, err
+	}
+	key := absolutePath(path)
+	journal, ok := journals[key]
+	if !ok {
+		journal = &fileJournal{}
+		journals[key] = journal
+	}
+
+	operation, err := change(journal)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	content, err := json.Marshal(journals)
+	if err != nil {
+		return Operation{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.Path), 0755); err != nil {
+		return Operation{}, err
+	}
+	return operation, replaceFile(j.Path, content)
+}
+
+func (j *Journal) load() (map[string]*fileJournal, error) {
+	journals := make(map[string]*fileJournal)
+	content, err := ioutil.ReadFile(j.Path)
+	if os.IsNotExist(err) {
+		return journals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &journals); err != nil {
+		return nil, err
+	}
+	return journals, nil
+}
+
+func (j *Journal) size() int {
+	if j.Size <= 0 {
+		return DefaultJournalSize
+	}
+	return j.Size
+}
+
+func (j *Journal) clock() Clock {
+	if j.Clock == nil {
+		return SystemClock
+	}
+	return j.Clock
+}
@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func addEntry(ip string, hostNames ...string) func(doc *parser.Document) error {
+	return func(doc *parser.Document) error {
+		_, err := doc.Add(hosts.NewEntryUnsafe(net.ParseIP(ip), hostNames))
+		return err
+	}
+}
+
+func TestJournal_UndoRedo(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	journal := &Journal{Path: filepath.Join(filepath.Dir(path), "journal.json")}
+	if err := journal.Edit(path, "add 10.1.2.3 api.prod", addEntry("10.1.2.3", "api.prod")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := journal.Edit(path, "add 10.1.2.4 web.prod", addEntry("10.1.2.4", "web.prod")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// changes of other tools are kept
+	content := "# managed by vpn\n10.8.0.1 vpn.local\n127.0.0.1 localhost\n10.1.2.3  api.prod\n10.1.2.4  web.prod\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	operation, err := journal.Undo(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if operation.Description != "add 10.1.2.4 web.prod" {
+		t.Errorf("unexpected operation %v", operation)
+	}
+	assertFileContent(path, "# managed by vpn\n10.8.0.1 vpn.local\n127.0.0.1 localhost\n10.1.2.3  api.prod\n", t)
+
+	if _, err := journal.Undo(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "# managed by vpn\n10.8.0.1 vpn.local\n127.0.0.1 localhost\n", t)
+
+	if _, err := journal.Undo(path); err != ErrorNothingToUndo {
+		t.Errorf("expected %v, actual %v", ErrorNothingToUndo, err)
+	}
+
+	if _, err := journal.Redo(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "# managed by vpn\n10.8.0.1 vpn.local\n127.0.0.1 localhost\n10.1.2.3  api.prod\n", t)
+
+	// a new operation drops the undone operations
+	if err := journal.Edit(path, "remove vpn.local", func(doc *parser.Document) error {
+		return doc.Remove("vpn.local")
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := journal.Redo(path); err != ErrorNothingToRedo {
+		t.Errorf("expected %v, actual %v", ErrorNothingToRedo, err)
+	}
+
+	operations, err := journal.Operations(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(operations) != 2 {
+		t.Fatalf("expected 2 operations, actual %v", operations)
+	}
+
+	if _, err := journal.Undo(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "# managed by vpn\n10.8.0.1 vpn.local\n127.0.0.1 localhost\n10.1.2.3  api.prod\n", t)
+}
+
+func TestJournal_Conflict(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	journal := &Journal{Path: filepath.Join(filepath.Dir(path), "journal.json")}
+	if err := journal.Edit(path, "add 10.1.2.3 api.prod", addEntry("10.1.2.3", "api.prod")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte("127.0.0.1 localhost\n10.1.2.5  api.prod\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err := journal.Undo(path); err != ErrorJournalConflict {
+		t.Errorf("expected %v, actual %v", ErrorJournalConflict, err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n10.1.2.5  api.prod\n", t)
+}
+
+func TestJournal_MutatorOperations(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n#10.1.2.3 api.prod\n", t)
+	defer cleanup()
+
+	journal := &Journal{Path: filepath.Join(filepath.Dir(path), "journal.json")}
+	err := journal.Edit(path, "", func(doc *parser.Document) error {
+		if err := doc.Enable("api.prod"); err != nil {
+			return err
+		}
+		return addEntry("10.1.2.4", "web.prod")(doc)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// edits without any change are not recorded
+	if err := journal.Edit(path, "", func(doc *parser.Document) error { return nil }); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	operations, err := journal.Operations(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(operations) != 1 || operations[0].Description != "enable api.prod, add 10.1.2.4 web.prod" {
+		t.Fatalf("expected the operations of the mutators, actual %v", operations)
+	}
+
+	if _, err := journal.Undo(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n#10.1.2.3 api.prod\n", t)
+	if _, err := journal.Redo(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1 localhost\n10.1.2.3 api.prod\n10.1.2.4  web.prod\n", t)
+}
@@ -0,0 +1,132 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"github.com/bitofcode/hosts"
+	"time"
+)
+
+// DefaultReapInterval is the maximum time a Reaper waits between two checks of the file.
+const DefaultReapInterval = time.Minute
+
+// A Clock provides the current time and timers, so time can be controlled in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock of the operating system.
+var SystemClock Clock = systemClock{}
+
+// A Reaper removes expired entries from the hosts file at Path.
+type Reaper struct {
+	Path string
+	// Clock defaults to SystemClock.
+	Clock Clock
+	// Interval defaults to DefaultReapInterval, the file is checked at least once per interval
+	// so entries added by other tools are noticed.
+	Interval time.Duration
+	// Notify is called by Run with the entries removed from the file, if not nil.
+	Notify func(expired []hosts.Entry)
+}
+
+// Reap removes all expired entries from the file and returns them.
+// The file is only rewritten if at least one entry expired.
+func (r *Reaper) Reap() ([]hosts.Entry, error) {
+	doc, err := ReadDocument(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := doc.Expire(r.clock().Now())
+	if len(expired) == 0 {
+		return expired, nil
+	}
+	return expired, WriteDocument(doc, r.Path)
+}
+
+// Run reaps the file until stop is closed or an error occurs.
+// It wakes up at the next expiry time or after Interval, whichever comes first.
+func (r *Reaper) Run(stop <-chan struct{}) error {
+	for {
+		expired, err := r.Reap()
+		if err != nil {
+			return err
+		}
+		if len(expired) > 0 && r.Notify != nil {
+			r.Notify(expired)
+		}
+
+		wait, err := r.nextWait()
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-r.clock().After(wait):
+		}
+	}
+}
+
+func (r *Reaper) nextWait() (time.Duration, error) {
+	wait := r.Interval
+	if wait <= 0 {
+		wait = DefaultReapInterval
+	}
+
+	doc, err := ReadDocument(r.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	if expiry, ok := doc.NextExpiry(); ok {
+		untilExpiry := expiry.Sub(r.clock().Now())
+		if untilExpiry < wait {
+			wait = untilExpiry
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, nil
+}
+
+func (r *Reaper) clock() Clock {
+	if r.Clock == nil {
+		return SystemClock
+	}
+	return r.Clock
+}
@@ -0,0 +1,135 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const reaperContent = `127.0.0.1  localhost
+10.1.2.3 api.prod # expires=2020-05-01T12:00:00Z
+# 10.1.2.4 web.prod # expires=2020-05-01T10:00:00Z
+10.1.2.5 db.prod # owner=payments expires=2020-05-01T14:00:00Z
+`
+
+// fakeClock advances its time to the deadline of every requested timer.
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	channel := make(chan time.Time, 1)
+	channel <- c.now
+	return channel
+}
+
+func createHostsFile(content string, t *testing.T) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "hostsfile")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	path = filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func assertFileContent(path string, expected string, t *testing.T) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(content) != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, string(content))
+	}
+}
+
+func TestReaper_Reap(t *testing.T) {
+	path, cleanup := createHostsFile(reaperContent, t)
+	defer cleanup()
+
+	reaper := &Reaper{Path: path, Clock: &fakeClock{now: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)}}
+	expired, err := reaper.Reap()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(expired) != 2 || !expired[0].Contains("api.prod") || !expired[1].Contains("web.prod") {
+		t.Errorf("unexpected expired entries %v", expired)
+	}
+	assertFileContent(path, "127.0.0.1  localhost\n10.1.2.5 db.prod # owner=payments expires=2020-05-01T14:00:00Z\n", t)
+}
+
+func TestReaper_ReapNothingExpired(t *testing.T) {
+	path, cleanup := createHostsFile(reaperContent, t)
+	defer cleanup()
+
+	reaper := &Reaper{Path: path, Clock: &fakeClock{now: time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)}}
+	expired, err := reaper.Reap()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(expired) != 0 {
+		t.Errorf("unexpected expired entries %v", expired)
+	}
+	assertFileContent(path, reaperContent, t)
+}
+
+func TestReaper_Run(t *testing.T) {
+	path, cleanup := createHostsFile(reaperContent, t)
+	defer cleanup()
+
+	clock := &fakeClock{now: time.Date(2020, 5, 1, 9, 0, 0, 0, time.UTC)}
+	reaper := &Reaper{Path: path, Clock: clock, Interval: 10 * time.Minute}
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- reaper.Run(stop) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !clock.Now().After(time.Date(2020, 5, 1, 14, 0, 0, 0, time.UTC)) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1  localhost\n", t)
+}
@@ -0,0 +1,139 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// A Version identifies the content of a file at the time it was read.
+type Version struct {
+	ModTime time.Time
+	Size    int64
+	// Hash is the hex encoded SHA-256 hash of the content, it decides whether two versions are equal.
+	Hash string
+}
+
+func (v Version) String() string {
+	hash := v.Hash
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	if hash == "" {
+		hash = "none"
+	}
+	return fmt.Sprintf("%s (%d bytes, modified %s)", hash, v.Size, v.ModTime.Format(time.RFC3339))
+}
+
+// VersionConflictError is returned by WriteIfUnchanged if the file was changed since the expected version was read.
+type VersionConflictError struct {
+	Path     string
+	Expected Version
+	// Actual is the zero Version if the file was removed.
+	Actual Version
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Actual.Hash == "" {
+		return fmt.Sprintf("%s was removed since version %s was read", e.Path, e.Expected)
+	}
+	return fmt.Sprintf("%s was changed from version %s to %s", e.Path, e.Expected, e.Actual)
+}
+
+// ReadVersion reads the file at path like Read and returns the version of the content which was read.
+func ReadVersion(path string) (hosts.EntrySet, Version, error) {
+	content, version, err := readContent(path)
+	if err != nil {
+		return nil, Version{}, err
+	}
+	entries, err := parser.ReadWithOrigin(bytes.NewReader(content), origin(path))
+	if err != nil {
+		return nil, Version{}, err
+	}
+	return entries, version, nil
+}
+
+// CurrentVersion returns the version of the current content of the file at path.
+func CurrentVersion(path string) (Version, error) {
+	_, version, err := readContent(path)
+	return version, err
+}
+
+// WriteIfUnchanged writes the entries to the file at path like Write, if the file still has the given version.
+// Otherwise a *VersionConflictError is returned and the file is left as it is.
+//
+// The file is not locked: a change by another process after the version was checked, while the BeforeWrite hooks
+// run and the temporary file is written, is overwritten. The check narrows the window of lost updates, it does
+// not close it.
+func WriteIfUnchanged(entries hosts.EntrySet, path string, version Version) error {
+	buffer := &bytes.Buffer{}
+	if err := parser.Write(entries, buffer); err != nil {
+		return err
+	}
+
+	return writeFileIf(path, buffer.Bytes(), func(old []byte) error {
+		if old == nil {
+			return &VersionConflictError{Path: path, Expected: version}
+		}
+		if hash := contentHash(old); hash != version.Hash {
+			actual := Version{Size: int64(len(old)), Hash: hash}
+			if info, err := os.Stat(path); err == nil {
+				actual.ModTime = info.ModTime()
+			}
+			return &VersionConflictError{Path: path, Expected: version, Actual: actual}
+		}
+		return nil
+	})
+}
+
+func readContent(path string) ([]byte, Version, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Version{}, err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, Version{}, err
+	}
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, Version{}, err
+	}
+	return content, Version{ModTime: info.ModTime(), Size: int64(len(content)), Hash: contentHash(content)}, nil
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hostsfile
+
+import (
+	"errors"
+	"github.com/bitofcode/hosts"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestWriteIfUnchanged(t *testing.T) {
+	path, cleanup := createHostsFile("127.0.0.1 localhost\n", t)
+	defer cleanup()
+
+	entries, version, err := ReadVersion(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if version.Size != 20 || version.ModTime.IsZero() {
+		t.Errorf("unexpected version %v", version)
+	}
+
+	entries.AddEntry(hosts.NewEntryUnsafe(net.ParseIP("10.1.2.3"), []string{"api.prod"}))
+	if err := WriteIfUnchanged(entries, path, version); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertFileContent(path, "127.0.0.1  localhost\n10.1.2.3  api.prod\n", t)
+
+	// the file moved on, the version which was read is outdated now
+	entries.AddEntry(hosts.NewEntryUnsafe(net.ParseIP("10.1.2.4"), []string{"web.prod"}))
+	err = WriteIfUnchanged(entries, path, version)
+	var conflict *VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a *VersionConflictError, actual %v", err)
+	}
+	current, err := CurrentVersion(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if conflict.Expected.Hash != version.Hash || conflict.Actual.Hash != current.Hash {
+		t.Errorf("unexpected conflict %v", conflict)
+	}
+	assertFileContent(path, "127.0.0.1  localhost\n10.1.2.3  api.prod\n", t)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := WriteIfUnchanged(entries, path, current); !errors.As(err, &conflict) || conflict.Actual.Hash != "" {
+		t.Errorf("expected a conflict for a removed file, actual %v", err)
+	}
+	if _, err := ioutil.ReadFile(path); !os.IsNotExist(err) {
+		t.Errorf("expected the removed file not to be written, actual %v", err)
+	}
+}
+
+func TestVersion_String(t *testing.T) {
+	tests := []struct {
+		version  Version
+		expected string
+	}{
+		{Version{Size: 20, Hash: "081ef9d53675a1b2c3d4e5f6"}, "081ef9d53675 (20 bytes, modified 0001-01-01T00:00:00Z)"},
+		{Version{Size: 20, Hash: "081ef9"}, "081ef9 (20 bytes, modified 0001-01-01T00:00:00Z)"},
+		// the actual version of a removed file
+		{Version{}, "none (0 bytes, modified 0001-01-01T00:00:00Z)"},
+	}
+	for _, test := range tests {
+		if actual := test.version.String(); actual != test.expected {
+			t.Errorf("expected '%s' actual '%s'", test.expected, actual)
+		}
+	}
+}
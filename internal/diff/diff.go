@@ -0,0 +1,233 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package diff computes line based differences between two texts.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// maxCells limits the size of the table used to find the longest common subsequence,
+// larger differences are reported as removing all old and adding all new lines.
+const maxCells = 4 << 20
+
+// An Op is the kind of an Edit.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// An Edit is one line of a difference. OldLine and NewLine are the 1-based line numbers
+// in the old and the new text, 0 if the line does not exist in the text.
+type Edit struct {
+	Op      Op
+	OldLine int
+	NewLine int
+	Text    string
+}
+
+// SplitLines splits the content into lines without their line endings.
+func SplitLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+	lines := strings.Split(string(bytes.TrimSuffix(content, []byte("\n"))), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// Lines returns the edits which transform the old lines into the new lines.
+func Lines(old, new []string) []Edit {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix &&
+		old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(old)+len(new))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Op: Equal, OldLine: i + 1, NewLine: i + 1, Text: old[i]})
+	}
+	edits = appendMiddle(edits, old[prefix:len(old)-suffix], new[prefix:len(new)-suffix], prefix, prefix)
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, Edit{Op: Equal, OldLine: len(old) - i + 1, NewLine: len(new) - i + 1, Text: old[len(old)-i]})
+	}
+	return edits
+}
+
+// appendMiddle appends the edits between old and new, which are preceded by the given number of lines.
+func appendMiddle(edits []Edit, old, new []string, oldOffset, newOffset int) []Edit {
+	n, m := len(old), len(new)
+	if n*m > maxCells {
+		for i, line := range old {
+			edits = append(edits, Edit{Op: Delete, OldLine: oldOffset + i + 1, Text: line})
+		}
+		for j, line := range new {
+			edits = append(edits, Edit{Op: Insert, NewLine: newOffset + j + 1, Text: line})
+		}
+		return edits
+	}
+
+	// common[i][j] is the length of the longest common subsequence of old[i:] and new[j:].
+	common := make([][]int, n+1)
+	for i := range common {
+		common[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && old[i] == new[j]:
+			edits = append(edits, Edit{Op: Equal, OldLine: oldOffset + i + 1, NewLine: newOffset + j + 1, Text: old[i]})
+			i++
+			j++
+		case j == m || (i < n && common[i+1][j] >= common[i][j+1]):
+			edits = append(edits, Edit{Op: Delete, OldLine: oldOffset + i + 1, Text: old[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, NewLine: newOffset + j + 1, Text: new[j]})
+			j++
+		}
+	}
+	return edits
+}
+
+// Changed reports whether the edits contain at least one deleted or inserted line.
+func Changed(edits []Edit) bool {
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified returns the edits in the unified diff format with the given number of context lines,
+// or an empty string if nothing changed.
+func Unified(edits []Edit, oldName, newName string, context int) string {
+	if !Changed(edits) {
+		return ""
+	}
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(edits); {
+		first := nextChange(edits, start)
+		if first == len(edits) {
+			break
+		}
+
+		// extend the hunk as long as the next change is close enough to share its context
+		last := first
+		for next := nextChange(edits, last+1); next < len(edits) && next-last <= 2*context+1; next = nextChange(edits, last+1) {
+			last = next
+		}
+
+		from := first - context
+		if from < start {
+			from = start
+		}
+		to := last + context + 1
+		if to > len(edits) {
+			to = len(edits)
+		}
+		writeHunk(builder, edits, from, to)
+		start = to
+	}
+	return builder.String()
+}
+
+func nextChange(edits []Edit, from int) int {
+	for i := from; i < len(edits); i++ {
+		if edits[i].Op != Equal {
+			return i
+		}
+	}
+	return len(edits)
+}
+
+func writeHunk(builder *strings.Builder, edits []Edit, from, to int) {
+	oldBefore, newBefore := count(edits[:from])
+	oldCount, newCount := count(edits[from:to])
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(oldBefore, oldCount), hunkRange(newBefore, newCount))
+	for _, edit := range edits[from:to] {
+		switch edit.Op {
+		case Equal:
+			builder.WriteString(" ")
+		case Delete:
+			builder.WriteString("-")
+		case Insert:
+			builder.WriteString("+")
+		}
+		builder.WriteString(edit.Text)
+		builder.WriteString("\n")
+	}
+}
+
+// count returns the number of old and new lines of the edits.
+func count(edits []Edit) (old, new int) {
+	for _, edit := range edits {
+		if edit.Op != Insert {
+			old++
+		}
+		if edit.Op != Delete {
+			new++
+		}
+	}
+	return old, new
+}
+
+// hunkRange formats the range of a hunk which follows the given number of lines,
+// an empty range refers to the line before it.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
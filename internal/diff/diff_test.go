@@ -0,0 +1,81 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package diff
+
+import (
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	old := SplitLines([]byte("a\nb\nc\nd\n"))
+	new := SplitLines([]byte("a\nc\nd\ne\n"))
+
+	expected := []Edit{
+		{Op: Equal, OldLine: 1, NewLine: 1, Text: "a"},
+		{Op: Delete, OldLine: 2, Text: "b"},
+		{Op: Equal, OldLine: 3, NewLine: 2, Text: "c"},
+		{Op: Equal, OldLine: 4, NewLine: 3, Text: "d"},
+		{Op: Insert, NewLine: 4, Text: "e"},
+	}
+	actual := Lines(old, new)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v actual %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %v actual %v", expected[i], actual[i])
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{name: "unchanged", old: "a\nb\n", new: "a\nb\n", expected: ""},
+		{name: "created", old: "", new: "a\n", expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{
+			name:     "separate hunks",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:      "0\n2\n3\n4\n5\n6\n7\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+0\n 2\n@@ -7,2 +7 @@\n 7\n-8\n",
+		},
+		{
+			name:     "merged hunk",
+			old:      "1\n2\n3\n",
+			new:      "0\n2\n4\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n-1\n+0\n 2\n-3\n+4\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edits := Lines(SplitLines([]byte(test.old)), SplitLines([]byte(test.new)))
+			if actual := Unified(edits, "old", "new", 1); actual != test.expected {
+				t.Errorf("expected '%s' actual '%s'", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"bytes"
+	"net"
+)
+
+// CompareIPs compares two ips for sorting, it returns -1 if a sorts before b, 1 if b sorts before a and 0 otherwise.
+// Loopback ips sort first, followed by IPv4 and then IPv6 ips, ips of the same family sort by their numeric value.
+func CompareIPs(a, b net.IP) int {
+	if aLoopback, bLoopback := a.IsLoopback(), b.IsLoopback(); aLoopback != bLoopback {
+		if aLoopback {
+			return -1
+		}
+		return 1
+	}
+
+	a4, b4 := a.To4(), b.To4()
+	switch {
+	case a4 != nil && b4 != nil:
+		return bytes.Compare(a4, b4)
+	case a4 != nil:
+		return -1
+	case b4 != nil:
+		return 1
+	}
+	return bytes.Compare(a.To16(), b.To16())
+}
@@ -0,0 +1,52 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompareIPs(t *testing.T) {
+	ips := make([]net.IP, 0)
+	for _, ip := range []string{"2001:db8::1", "9.9.9.9", "::1", "10.0.0.2", "127.0.0.2", "fe80::1", "127.0.0.1", "10.0.0.10"} {
+		ips = append(ips, net.ParseIP(ip))
+	}
+
+	sort.Slice(ips, func(i, j int) bool { return CompareIPs(ips[i], ips[j]) < 0 })
+
+	sorted := make([]string, len(ips))
+	for i, ip := range ips {
+		sorted[i] = ip.String()
+	}
+	expected := "127.0.0.1 127.0.0.2 ::1 9.9.9.9 10.0.0.2 10.0.0.10 2001:db8::1 fe80::1"
+	if actual := strings.Join(sorted, " "); actual != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, actual)
+	}
+
+	if CompareIPs(net.ParseIP("10.0.0.1"), net.ParseIP("::ffff:10.0.0.1")) != 0 {
+		t.Errorf("expected an IPv4 ip to be equal to its IPv4-mapped IPv6 form")
+	}
+}
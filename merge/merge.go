@@ -0,0 +1,496 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+/*
+Package merge merges two changed versions of a hosts file with their common base version.
+
+Changes which do not overlap are taken line by line. Where both sides changed the same lines, the changes are
+merged per host name: a host name changed by only one side takes the value of that side. A host name which both
+sides changed in different ways is a conflict, the lines are then enclosed in conflict markers. This includes
+host names whose changes did not overlap line by line, e.g. one side changed the ip of a host name and the other
+side added it on another line.
+*/
+package merge
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/internal/diff"
+	"github.com/bitofcode/hosts/parser"
+	"sort"
+	"strings"
+)
+
+// DefaultMarkerSize is the length of the conflict markers, e.g. '<<<<<<<'.
+const DefaultMarkerSize = 7
+
+// A Conflict is a host name which was changed by both sides in different ways.
+// The values are the ips of the host name in the conflicting lines, ips of disabled entries are prefixed
+// by a comment sign. A conflict without host name is a comment or blank line which both sides changed in
+// different ways, the values are then the comment and blank lines of the conflicting lines.
+type Conflict struct {
+	HostName string
+	Base     []string
+	Ours     []string
+	Theirs   []string
+}
+
+func (c Conflict) String() string {
+	if c.HostName == "" {
+		return fmt.Sprintf("lines: base %q, ours %q, theirs %q", c.Base, c.Ours, c.Theirs)
+	}
+	return fmt.Sprintf("%s: base %v, ours %v, theirs %v", c.HostName, c.Base, c.Ours, c.Theirs)
+}
+
+// key identifies the conflict, a host name or the lines of a conflict without host name.
+func (c Conflict) key() string {
+	if c.HostName == "" {
+		return c.String()
+	}
+	return c.HostName
+}
+
+// A Chunk is a part of the merged file, either resolved lines or the conflicting lines of all three versions.
+type Chunk struct {
+	Lines    []string
+	Conflict bool
+	Base     []string
+	Ours     []string
+	Theirs   []string
+}
+
+// A Result is the outcome of a merge.
+type Result struct {
+	Chunks    []Chunk
+	Conflicts []Conflict
+}
+
+// Markers are the labels of the conflict markers.
+type Markers struct {
+	// Size defaults to DefaultMarkerSize.
+	Size   int
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// Clean reports whether the merge has no conflicts.
+func (r *Result) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// Bytes returns the merged content, conflicting chunks are enclosed by the given markers.
+func (r *Result) Bytes(markers Markers) []byte {
+	size := markers.Size
+	if size <= 0 {
+		size = DefaultMarkerSize
+	}
+	marker := func(sign string, label string) string {
+		return strings.TrimSpace(strings.Repeat(sign, size) + " " + label)
+	}
+
+	buffer := &bytes.Buffer{}
+	writeLines := func(lines ...string) {
+		for _, line := range lines {
+			buffer.WriteString(line)
+			buffer.WriteString("\n")
+		}
+	}
+	for _, chunk := range r.Chunks {
+		if !chunk.Conflict {
+			writeLines(chunk.Lines...)
+			continue
+		}
+		writeLines(marker("<", markers.Ours))
+		writeLines(chunk.Ours...)
+		writeLines(marker("|", markers.Base))
+		writeLines(chunk.Base...)
+		writeLines(marker("=", ""))
+		writeLines(chunk.Theirs...)
+		writeLines(marker(">", markers.Theirs))
+	}
+	return buffer.Bytes()
+}
+
+// Merge merges the changes of ours and theirs to base.
+func Merge(base, ours, theirs *parser.Document) *Result {
+	b, o, t := base.Lines(), ours.Lines(), theirs.Lines()
+	matchesOurs, matchesTheirs := match(b, o), match(b, t)
+
+	result := &Result{Chunks: make([]Chunk, 0), Conflicts: make([]Conflict, 0)}
+	marked := make(map[string]bool)
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		if i < len(b) && matchesOurs[i] == j && matchesTheirs[i] == k {
+			result.appendLines(b[i].Raw())
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// the chunk ends at the next base line which is unchanged on both sides
+		next, endOurs, endTheirs := i, len(o), len(t)
+		for next < len(b) && (matchesOurs[next] < 0 || matchesTheirs[next] < 0) {
+			next++
+		}
+		if next < len(b) {
+			endOurs, endTheirs = matchesOurs[next], matchesTheirs[next]
+		}
+		for _, conflict := range result.mergeChunk(b[i:next], o[j:endOurs], t[k:endTheirs]) {
+			marked[conflict.HostName] = true
+		}
+		i, j, k = next, endOurs, endTheirs
+	}
+
+	conflicts := findConflicts(mappings(b), mappings(o), mappings(t), nil)
+	result.addConflicts(conflicts)
+	unmarked := make(map[string]bool)
+	for _, conflict := range conflicts {
+		if !marked[conflict.HostName] {
+			unmarked[conflict.HostName] = true
+		}
+	}
+	if len(unmarked) > 0 {
+		result.markConflicts(unmarked, b, o, t)
+	}
+	return result
+}
+
+// match returns for every base line the index of the same line in other, or -1 if it was changed.
+func match(base, other []*parser.Line) []int {
+	matches := make([]int, len(base))
+	for i := range matches {
+		matches[i] = -1
+	}
+	for _, edit := range diff.Lines(raws(base), raws(other)) {
+		if edit.Op == diff.Equal {
+			matches[edit.OldLine-1] = edit.NewLine - 1
+		}
+	}
+	return matches
+}
+
+func (r *Result) appendLines(lines ...string) {
+	if len(lines) == 0 {
+		return
+	}
+	if last := len(r.Chunks) - 1; last >= 0 && !r.Chunks[last].Conflict {
+		r.Chunks[last].Lines = append(r.Chunks[last].Lines, lines...)
+		return
+	}
+	r.Chunks = append(r.Chunks, Chunk{Lines: append([]string{}, lines...)})
+}
+
+// addConflicts adds the conflicts of host names which are not known yet.
+func (r *Result) addConflicts(conflicts []Conflict) {
+	known := make(map[string]bool)
+	for _, conflict := range r.Conflicts {
+		known[conflict.key()] = true
+	}
+	for _, conflict := range conflicts {
+		if !known[conflict.key()] {
+			r.Conflicts = append(r.Conflicts, conflict)
+		}
+	}
+	sort.Slice(r.Conflicts, func(i, j int) bool {
+		return r.Conflicts[i].HostName < r.Conflicts[j].HostName
+	})
+}
+
+// mergeChunk merges lines changed by at least one side and returns the conflicts enclosed in conflict markers.
+func (r *Result) mergeChunk(base, ours, theirs []*parser.Line) []Conflict {
+	rawBase, rawOurs, rawTheirs := raws(base), raws(ours), raws(theirs)
+	switch {
+	case equal(rawOurs, rawBase):
+		r.appendLines(rawTheirs...)
+	case equal(rawTheirs, rawBase) || equal(rawOurs, rawTheirs):
+		r.appendLines(rawOurs...)
+	default:
+		lines, conflicts := mergeHostNames(base, ours, theirs)
+		if len(conflicts) == 0 {
+			r.appendLines(lines...)
+			return nil
+		}
+		r.Chunks = append(r.Chunks, Chunk{Conflict: true, Base: rawBase, Ours: rawOurs, Theirs: rawTheirs})
+		r.addConflicts(conflicts)
+		return conflicts
+	}
+	return nil
+}
+
+// markConflicts encloses the conflicting host names, whose changes were merged line by line, in conflict markers.
+// Their lines in all three versions replace the merged lines as one conflicting chunk, at the first merged line
+// which contains one of the host names.
+func (r *Result) markConflicts(hostNames map[string]bool, base, ours, theirs []*parser.Line) {
+	conflict := Chunk{
+		Conflict: true,
+		Base:     linesOf(base, hostNames),
+		Ours:     linesOf(ours, hostNames),
+		Theirs:   linesOf(theirs, hostNames),
+	}
+
+	chunks, placed := r.Chunks, false
+	r.Chunks = make([]Chunk, 0, len(chunks)+1)
+	for _, chunk := range chunks {
+		if chunk.Conflict {
+			r.Chunks = append(r.Chunks, chunk)
+			continue
+		}
+		for _, line := range chunk.Lines {
+			if !containsHostName(line, hostNames) {
+				r.appendLines(line)
+			} else if !placed {
+				r.Chunks = append(r.Chunks, conflict)
+				placed = true
+			}
+		}
+	}
+	if !placed {
+		r.Chunks = append(r.Chunks, conflict)
+	}
+}
+
+// mergeHostNames merges lines changed by both sides per host name. Our lines are kept, except for the host names
+// only theirs changed, which are taken from their lines. Comment lines removed by theirs are dropped and comment
+// lines added by theirs are appended, unless both sides changed the same comment lines in different ways.
+func mergeHostNames(base, ours, theirs []*parser.Line) ([]string, []Conflict) {
+	takeTheirs := make(map[string]bool)
+	conflicts := findConflicts(mappings(base), mappings(ours), mappings(theirs), takeTheirs)
+	if conflict, ok := findLineConflict(base, ours, theirs); ok {
+		conflicts = append(conflicts, conflict)
+	}
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+
+	inBase, inOurs, inTheirs := set(raws(base)), set(raws(ours)), set(raws(theirs))
+	lines := make([]string, 0, len(ours)+len(theirs))
+	for _, line := range ours {
+		if line.Entry() == nil {
+			if !inBase[line.Raw()] || inTheirs[line.Raw()] {
+				lines = append(lines, line.Raw())
+			}
+			continue
+		}
+		kept := filterHostNames(line.Entry(), func(hostName string) bool { return !takeTheirs[hostName] })
+		if len(kept) == len(line.Entry().HostNames()) {
+			lines = append(lines, line.Raw())
+		} else if len(kept) > 0 {
+			lines = append(lines, render(line, kept))
+		}
+	}
+
+	for _, line := range theirs {
+		if line.Entry() == nil {
+			if !inBase[line.Raw()] && !inOurs[line.Raw()] {
+				lines = append(lines, line.Raw())
+			}
+			continue
+		}
+		taken := filterHostNames(line.Entry(), func(hostName string) bool { return takeTheirs[hostName] })
+		if len(taken) == len(line.Entry().HostNames()) {
+			lines = append(lines, line.Raw())
+		} else if len(taken) > 0 {
+			lines = append(lines, render(line, taken))
+		}
+	}
+	return lines, nil
+}
+
+// findConflicts returns the host names which both sides changed in different ways.
+// Host names which only theirs changed are added to takeTheirs, if not nil.
+func findConflicts(base, ours, theirs map[string][]string, takeTheirs map[string]bool) []Conflict {
+	hostNames := make(map[string]bool)
+	for _, mapping := range []map[string][]string{base, ours, theirs} {
+		for hostName := range mapping {
+			hostNames[hostName] = true
+		}
+	}
+
+	conflicts := make([]Conflict, 0)
+	for hostName := range hostNames {
+		inBase, inOurs, inTheirs := base[hostName], ours[hostName], theirs[hostName]
+		switch {
+		case equal(inOurs, inTheirs) || equal(inTheirs, inBase):
+		case equal(inOurs, inBase):
+			if takeTheirs != nil {
+				takeTheirs[hostName] = true
+			}
+		default:
+			conflicts = append(conflicts, Conflict{HostName: hostName, Base: inBase, Ours: inOurs, Theirs: inTheirs})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].HostName < conflicts[j].HostName
+	})
+	return conflicts
+}
+
+// findLineConflict returns the conflict of the comment and blank lines, if both sides changed a base line and
+// replaced it with different lines (a removed line is replaced with none).
+func findLineConflict(base, ours, theirs []*parser.Line) (Conflict, bool) {
+	b, o, t := nonEntryLines(base), nonEntryLines(ours), nonEntryLines(theirs)
+	inBase, inOurs, inTheirs := set(b), set(o), set(t)
+
+	changedByBoth := false
+	for _, line := range b {
+		if !inOurs[line] && !inTheirs[line] {
+			changedByBoth = true
+		}
+	}
+	if !changedByBoth || equal(added(o, inBase), added(t, inBase)) {
+		return Conflict{}, false
+	}
+	return Conflict{Base: b, Ours: o, Theirs: t}, true
+}
+
+// nonEntryLines returns the comment and blank lines.
+func nonEntryLines(lines []*parser.Line) []string {
+	texts := make([]string, 0)
+	for _, line := range lines {
+		if line.Entry() == nil {
+			texts = append(texts, line.Raw())
+		}
+	}
+	return texts
+}
+
+// added returns the sorted lines which are not in base.
+func added(lines []string, inBase map[string]bool) []string {
+	result := make([]string, 0)
+	for _, line := range lines {
+		if !inBase[line] {
+			result = append(result, line)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// mappings returns the sorted ips of every host name, ips of disabled lines are prefixed by a comment sign.
+func mappings(lines []*parser.Line) map[string][]string {
+	mapping := make(map[string][]string)
+	for _, line := range lines {
+		entry := line.Entry()
+		if entry == nil {
+			continue
+		}
+		value := entry.IpString()
+		if line.Disabled() {
+			value = "# " + value
+		}
+		for _, hostName := range entry.HostNames() {
+			mapping[hostName] = append(mapping[hostName], value)
+		}
+	}
+	for _, values := range mapping {
+		sort.Strings(values)
+	}
+	return mapping
+}
+
+// linesOf returns the lines which contain one of the host names.
+func linesOf(lines []*parser.Line, hostNames map[string]bool) []string {
+	contained := make([]string, 0)
+	for _, line := range lines {
+		if line.Entry() != nil && containsAny(line.Entry(), hostNames) {
+			contained = append(contained, line.Raw())
+		}
+	}
+	return contained
+}
+
+// containsHostName reports whether the merged line contains one of the host names.
+func containsHostName(raw string, hostNames map[string]bool) bool {
+	doc, err := parser.ReadDocument(strings.NewReader(raw))
+	if err != nil {
+		return false
+	}
+	for _, line := range doc.Lines() {
+		if line.Entry() != nil && containsAny(line.Entry(), hostNames) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(entry hosts.Entry, hostNames map[string]bool) bool {
+	for _, hostName := range entry.HostNames() {
+		if hostNames[hostName] {
+			return true
+		}
+	}
+	return false
+}
+
+func filterHostNames(entry hosts.Entry, keep func(hostName string) bool) []string {
+	hostNames := make([]string, 0)
+	for _, hostName := range entry.HostNames() {
+		if keep(hostName) {
+			hostNames = append(hostNames, hostName)
+		}
+	}
+	return hostNames
+}
+
+// render returns the line with only the given host names.
+func render(line *parser.Line, hostNames []string) string {
+	entry := hosts.NewEntryUnsafe(line.Entry().Ip(), hostNames)
+	entry.SetComment(line.Entry().Comment())
+	raw, err := parser.WriteToLine(entry)
+	if err != nil {
+		// the host names were parsed from the line, so they are valid
+		return line.Raw()
+	}
+	if line.Disabled() {
+		raw = "# " + raw
+	}
+	return raw
+}
+
+func raws(lines []*parser.Line) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Raw()
+	}
+	return texts
+}
+
+func set(values []string) map[string]bool {
+	contained := make(map[string]bool)
+	for _, value := range values {
+		contained[value] = true
+	}
+	return contained
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
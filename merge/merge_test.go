@@ -0,0 +1,143 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package merge
+
+import (
+	"github.com/bitofcode/hosts/parser"
+	"strings"
+	"testing"
+)
+
+func mustReadDocument(content string, t *testing.T) *parser.Document {
+	doc, err := parser.ReadDocument(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return doc
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		ours      string
+		theirs    string
+		expected  string
+		conflicts []string
+	}{
+		{
+			name:     "separate lines",
+			base:     "127.0.0.1 localhost\n10.0.0.1 a\n10.0.0.2 b\n10.0.0.3 c\n",
+			ours:     "127.0.0.1 localhost\n10.0.0.5 a\n10.0.0.2 b\n10.0.0.3 c\n",
+			theirs:   "127.0.0.1 localhost\n10.0.0.1 a\n10.0.0.2 b\n# 10.0.0.3 c\n10.0.0.4 d\n",
+			expected: "127.0.0.1 localhost\n10.0.0.5 a\n10.0.0.2 b\n# 10.0.0.3 c\n10.0.0.4 d\n",
+		},
+		{
+			name:     "same line, different host names",
+			base:     "# vpn\n10.0.0.1 a b\n",
+			ours:     "# vpn\n10.0.0.1 a b c\n",
+			theirs:   "# vpn hosts\n10.0.0.1 a\n",
+			expected: "10.0.0.1  a  c\n# vpn hosts\n",
+		},
+		{
+			name:     "same change",
+			base:     "127.0.0.1 localhost\n",
+			ours:     "127.0.0.1 localhost\n10.0.0.1 a\n",
+			theirs:   "127.0.0.1 localhost\n10.0.0.1 a\n",
+			expected: "127.0.0.1 localhost\n10.0.0.1 a\n",
+		},
+		{
+			name:      "conflict",
+			base:      "127.0.0.1 localhost\n10.0.0.1 a\n",
+			ours:      "127.0.0.1 localhost\n10.0.0.5 a\n",
+			theirs:    "127.0.0.1 localhost\n10.0.0.6 a\n",
+			expected:  "127.0.0.1 localhost\n<<<<<<< ours\n10.0.0.5 a\n||||||| base\n10.0.0.1 a\n=======\n10.0.0.6 a\n>>>>>>> theirs\n",
+			conflicts: []string{"a: base [10.0.0.1], ours [10.0.0.5], theirs [10.0.0.6]"},
+		},
+		{
+			name:      "removed and changed",
+			base:      "127.0.0.1 localhost\n10.0.0.1 a\n",
+			ours:      "127.0.0.1 localhost\n",
+			theirs:    "127.0.0.1 localhost\n10.0.0.2 a\n",
+			expected:  "127.0.0.1 localhost\n<<<<<<< ours\n||||||| base\n10.0.0.1 a\n=======\n10.0.0.2 a\n>>>>>>> theirs\n",
+			conflicts: []string{"a: base [10.0.0.1], ours [], theirs [10.0.0.2]"},
+		},
+		{
+			name:      "same comment changed differently",
+			base:      "# vpn\n10.0.0.1 a\n",
+			ours:      "# vpn ours\n10.0.0.1 a\n",
+			theirs:    "# vpn theirs\n10.0.0.1 a\n",
+			expected:  "<<<<<<< ours\n# vpn ours\n||||||| base\n# vpn\n=======\n# vpn theirs\n>>>>>>> theirs\n10.0.0.1 a\n",
+			conflicts: []string{`lines: base ["# vpn"], ours ["# vpn ours"], theirs ["# vpn theirs"]`},
+		},
+		{
+			name:      "comment changed and removed",
+			base:      "127.0.0.1 localhost\n\n# vpn\n10.0.0.1 a\n",
+			ours:      "127.0.0.1 localhost\n\n# vpn hosts\n10.0.0.1 a b\n",
+			theirs:    "127.0.0.1 localhost\n\n10.0.0.1 a\n",
+			expected:  "127.0.0.1 localhost\n\n<<<<<<< ours\n# vpn hosts\n10.0.0.1 a b\n||||||| base\n# vpn\n10.0.0.1 a\n=======\n10.0.0.1 a\n>>>>>>> theirs\n",
+			conflicts: []string{`lines: base ["# vpn"], ours ["# vpn hosts"], theirs []`},
+		},
+		{
+			name:     "same comment changed equally",
+			base:     "# vpn\n10.0.0.1 a\n",
+			ours:     "# vpn hosts\n10.0.0.1 a b\n",
+			theirs:   "# vpn hosts\n10.0.0.1 a\n",
+			expected: "# vpn hosts\n10.0.0.1 a b\n",
+		},
+		{
+			name:      "changed and added",
+			base:      "10.0.0.1 a\n",
+			ours:      "10.0.0.5 a\n",
+			theirs:    "10.0.0.1 a\n10.0.0.6 a\n",
+			expected:  "<<<<<<< ours\n10.0.0.5 a\n||||||| base\n10.0.0.1 a\n=======\n10.0.0.1 a\n10.0.0.6 a\n>>>>>>> theirs\n",
+			conflicts: []string{"a: base [10.0.0.1], ours [10.0.0.5], theirs [10.0.0.1 10.0.0.6]"},
+		},
+		{
+			name:   "changed and added on separate lines",
+			base:   "10.0.0.1 a\n127.0.0.1 localhost\n10.0.0.2 b\n",
+			ours:   "10.0.0.5 a\n127.0.0.1 localhost\n10.0.0.2 b\n",
+			theirs: "10.0.0.1 a\n127.0.0.1 localhost\n10.0.0.2 b\n10.0.0.6 a\n",
+			expected: "<<<<<<< ours\n10.0.0.5 a\n||||||| base\n10.0.0.1 a\n=======\n10.0.0.1 a\n10.0.0.6 a\n>>>>>>> theirs\n" +
+				"127.0.0.1 localhost\n10.0.0.2 b\n",
+			conflicts: []string{"a: base [10.0.0.1], ours [10.0.0.5], theirs [10.0.0.1 10.0.0.6]"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Merge(mustReadDocument(test.base, t), mustReadDocument(test.ours, t), mustReadDocument(test.theirs, t))
+
+			actual := string(result.Bytes(Markers{Base: "base", Ours: "ours", Theirs: "theirs"}))
+			if actual != test.expected {
+				t.Errorf("expected '%s' actual '%s'", test.expected, actual)
+			}
+			conflicts := make([]string, len(result.Conflicts))
+			for i, conflict := range result.Conflicts {
+				conflicts[i] = conflict.String()
+			}
+			if strings.Join(conflicts, "\n") != strings.Join(test.conflicts, "\n") || result.Clean() != (len(conflicts) == 0) {
+				t.Errorf("expected conflicts %v actual %v", test.conflicts, conflicts)
+			}
+		})
+	}
+}
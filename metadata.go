@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import "strings"
+
+const metadataSeparator = "="
+
+// MetadataExpires is the metadata key of the expiry time of an entry (RFC 3339, e.g. 'expires=2020-05-01T12:00:00Z').
+const MetadataExpires = "expires"
+
+// MetadataTags is the metadata key of the comma separated tags of an entry (e.g. 'tags=billing,dev').
+const MetadataTags = "tags"
+
+// ParseMetadata returns all key=value pairs of the given comment, e.g. 'owner=payments ticket=OPS-12'.
+// Words without '=' are free text and ignored, the last value of a repeated key wins.
+func ParseMetadata(comment string) map[string]string {
+	metadata := make(map[string]string)
+	for _, field := range strings.Fields(comment) {
+		if key, value, ok := splitMetadata(field); ok {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+func splitMetadata(field string) (key, value string, ok bool) {
+	position := strings.Index(field, metadataSeparator)
+	if position <= 0 {
+		return "", "", false
+	}
+	return field[:position], field[position+len(metadataSeparator):], true
+}
+
+func isValidMetadataKey(key string) bool {
+	return key != "" && !strings.Contains(key, metadataSeparator) && isValidMetadataValue(key)
+}
+
+func isValidMetadataValue(value string) bool {
+	return !strings.ContainsAny(value, " \t\n\f\r\v")
+}
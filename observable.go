@@ -0,0 +1,199 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// An Event describes a change of an ObservableEntrySet, it is one of HostAdded, HostRemoved or IPRemoved.
+type Event interface {
+	fmt.Stringer
+	isEvent()
+}
+
+// HostAdded is emitted when a host name is mapped to an ip it was not mapped to before.
+type HostAdded struct {
+	IP       net.IP
+	HostName string
+}
+
+// HostRemoved is emitted when a host name is no longer mapped to an ip.
+type HostRemoved struct {
+	IP       net.IP
+	HostName string
+}
+
+// IPRemoved is emitted after the HostRemoved events of the last host names of an ip.
+type IPRemoved struct {
+	IP net.IP
+}
+
+func (HostAdded) isEvent()   {}
+func (HostRemoved) isEvent() {}
+func (IPRemoved) isEvent()   {}
+
+func (e HostAdded) String() string {
+	return fmt.Sprintf("host added: %s %s", e.IP, e.HostName)
+}
+
+func (e HostRemoved) String() string {
+	return fmt.Sprintf("host removed: %s %s", e.IP, e.HostName)
+}
+
+func (e IPRemoved) String() string {
+	return fmt.Sprintf("ip removed: %s", e.IP)
+}
+
+// A Listener receives the events of an ObservableEntrySet.
+type Listener func(event Event)
+
+type subscription struct {
+	id       int
+	listener Listener
+}
+
+// ObservableEntrySet wraps an EntrySet and emits an Event for every change to its listeners.
+// Listeners are called synchronously in the goroutine which changes the set, after the change was applied,
+// in the order they subscribed. A listener must not subscribe or unsubscribe listeners itself.
+type ObservableEntrySet struct {
+	MutableEntrySet
+	mutex         sync.RWMutex
+	subscriptions []subscription
+	nextId        int
+}
+
+// NewObservableEntrySet returns an ObservableEntrySet which wraps the given MutableEntrySet.
+// Changes made directly on the wrapped MutableEntrySet are not observed.
+func NewObservableEntrySet(entrySet MutableEntrySet) *ObservableEntrySet {
+	return &ObservableEntrySet{MutableEntrySet: entrySet}
+}
+
+// Subscribe registers the given listener and returns a function which removes it again.
+func (o *ObservableEntrySet) Subscribe(listener Listener) (unsubscribe func()) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	id := o.nextId
+	o.nextId++
+	o.subscriptions = append(o.subscriptions, subscription{id: id, listener: listener})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { o.unsubscribe(id) })
+	}
+}
+
+func (o *ObservableEntrySet) unsubscribe(id int) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for i, s := range o.subscriptions {
+		if s.id == id {
+			o.subscriptions = append(o.subscriptions[:i:i], o.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Channel returns a channel which receives all events and a function which closes it.
+// Changes of the set block until the event is received (or buffered), unless the channel is closed.
+func (o *ObservableEntrySet) Channel(buffer int) (events <-chan Event, unsubscribe func()) {
+	channel := make(chan Event, buffer)
+	done := make(chan struct{})
+
+	remove := o.Subscribe(func(event Event) {
+		select {
+		case channel <- event:
+		case <-done:
+		}
+	})
+
+	var once sync.Once
+	return channel, func() {
+		once.Do(func() {
+			close(done)
+			remove()
+			close(channel)
+		})
+	}
+}
+
+func (o *ObservableEntrySet) AddEntry(entry Entry, entries ...Entry) {
+	for _, en := range append([]Entry{entry}, entries...) {
+		before, _ := o.MutableEntrySet.EntriesOfIP(en.Ip())
+		o.MutableEntrySet.AddEntry(en)
+		after, _ := o.MutableEntrySet.EntriesOfIP(en.Ip())
+
+		known := make(map[string]bool)
+		for _, hostName := range before {
+			known[hostName] = true
+		}
+		for _, hostName := range after {
+			if !known[hostName] {
+				o.emit(HostAdded{IP: en.Ip(), HostName: hostName})
+			}
+		}
+	}
+}
+
+func (o *ObservableEntrySet) RemoveHostName(hostName string) bool {
+	hostName = strings.ToLower(hostName)
+	ips, _ := o.MutableEntrySet.LookupHost(hostName)
+	if !o.MutableEntrySet.RemoveHostName(hostName) {
+		return false
+	}
+
+	for _, ip := range ips {
+		o.emit(HostRemoved{IP: ip, HostName: hostName})
+		if _, ok := o.MutableEntrySet.EntriesOfIP(ip); !ok {
+			o.emit(IPRemoved{IP: ip})
+		}
+	}
+	return true
+}
+
+func (o *ObservableEntrySet) RemoveIP(ip net.IP) bool {
+	hostNames, _ := o.MutableEntrySet.EntriesOfIP(ip)
+	if !o.MutableEntrySet.RemoveIP(ip) {
+		return false
+	}
+
+	for _, hostName := range hostNames {
+		o.emit(HostRemoved{IP: ip, HostName: hostName})
+	}
+	o.emit(IPRemoved{IP: ip})
+	return true
+}
+
+func (o *ObservableEntrySet) emit(event Event) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	for _, s := range o.subscriptions {
+		s.listener(event)
+	}
+}
@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"testing"
+)
+
+func eventStrings(events []Event) []string {
+	descriptions := make([]string, len(events))
+	for i, event := range events {
+		descriptions[i] = event.String()
+	}
+	return descriptions
+}
+
+func assertEvents(expected []string, actual []Event, t *testing.T) {
+	descriptions := eventStrings(actual)
+	if len(descriptions) != len(expected) {
+		t.Fatalf("expected events %v, actual %v", expected, descriptions)
+	}
+	for i := range expected {
+		if descriptions[i] != expected[i] {
+			t.Errorf("expected events %v, actual %v", expected, descriptions)
+		}
+	}
+}
+
+func TestObservableEntrySet_Subscribe(t *testing.T) {
+	entries := NewObservableEntrySet(NewEntrySet())
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	events := make([]Event, 0)
+	unsubscribe := entries.Subscribe(func(event Event) { events = append(events, event) })
+
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "api.local"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"web.local"}))
+	entries.RemoveHostName("API.local")
+	entries.RemoveHostName("web.local")
+	entries.RemoveHostName("unknown.local")
+	entries.RemoveIP(net.ParseIP("127.0.0.1"))
+	entries.RemoveIP(net.ParseIP("127.0.0.1"))
+
+	assertEvents([]string{
+		"host added: 127.0.0.1 api.local",
+		"host added: 10.0.0.1 web.local",
+		"host removed: 127.0.0.1 api.local",
+		"host removed: 10.0.0.1 web.local",
+		"ip removed: 10.0.0.1",
+		"host removed: 127.0.0.1 localhost",
+		"ip removed: 127.0.0.1",
+	}, events, t)
+
+	unsubscribe()
+	unsubscribe()
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+	if len(events) != 7 {
+		t.Errorf("expected no events after unsubscribe, actual %v", eventStrings(events))
+	}
+}
+
+func TestObservableEntrySet_Channel(t *testing.T) {
+	entries := NewObservableEntrySet(NewEntrySet())
+	channel, unsubscribe := entries.Channel(0)
+
+	received := make(chan []Event)
+	go func() {
+		events := make([]Event, 0)
+		for event := range channel {
+			events = append(events, event)
+		}
+		received <- events
+	}()
+
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"}))
+	entries.RemoveIP(net.ParseIP("10.0.0.1"))
+	unsubscribe()
+
+	assertEvents([]string{
+		"host added: 10.0.0.1 api.local",
+		"host removed: 10.0.0.1 api.local",
+		"ip removed: 10.0.0.1",
+	}, <-received, t)
+
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"}))
+}
+
+func TestObservableEntrySet_UnsubscribeUnblocksChannel(t *testing.T) {
+	entries := NewObservableEntrySet(NewEntrySet())
+	_, unsubscribe := entries.Channel(0)
+
+	done := make(chan struct{})
+	go func() {
+		entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"}))
+		close(done)
+	}()
+
+	unsubscribe()
+	<-done
+}
@@ -0,0 +1,61 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import "fmt"
+
+// An Origin describes where a host name was defined.
+type Origin struct {
+	// Source is a short name of the source, e.g. the name of a fragment.
+	Source string
+	// Path is the path of the file, if the source is a file.
+	Path string
+	// Line is the 1-based line number inside the source, 0 if unknown.
+	Line int
+}
+
+// IsZero reports whether nothing is known about the origin.
+func (o Origin) IsZero() bool {
+	return o == Origin{}
+}
+
+// String returns the origin as 'source (path:line)', unknown parts are left out.
+func (o Origin) String() string {
+	location := o.Path
+	if o.Line > 0 {
+		if location == "" {
+			location = fmt.Sprintf("line %d", o.Line)
+		} else {
+			location = fmt.Sprintf("%s:%d", location, o.Line)
+		}
+	}
+
+	switch {
+	case o.Source == "":
+		return location
+	case location == "":
+		return o.Source
+	default:
+		return fmt.Sprintf("%s (%s)", o.Source, location)
+	}
+}
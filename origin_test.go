@@ -0,0 +1,111 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"testing"
+)
+
+func TestOrigin_String(t *testing.T) {
+	tests := []struct {
+		origin   Origin
+		expected string
+	}{
+		{origin: Origin{}, expected: ""},
+		{origin: Origin{Source: "vpn"}, expected: "vpn"},
+		{origin: Origin{Line: 3}, expected: "line 3"},
+		{origin: Origin{Source: "vpn", Line: 3}, expected: "vpn (line 3)"},
+		{origin: Origin{Path: "/etc/hosts.d/vpn.hosts", Line: 3}, expected: "/etc/hosts.d/vpn.hosts:3"},
+		{origin: Origin{Source: "vpn", Path: "/etc/hosts.d/vpn.hosts", Line: 3}, expected: "vpn (/etc/hosts.d/vpn.hosts:3)"},
+	}
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			if test.origin.String() != test.expected {
+				t.Errorf("expected '%s' actual '%s'", test.expected, test.origin.String())
+			}
+		})
+	}
+}
+
+func TestSimpleEntry_SetOrigin(t *testing.T) {
+	entry := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"})
+	origin := Origin{Source: "vpn", Path: "/etc/hosts.d/vpn.hosts", Line: 3}
+	entry.SetOrigin("API.local", origin)
+	entry.SetOrigin("unknown.local", origin)
+
+	if actual, ok := entry.Origin("api.local"); !ok || actual != origin {
+		t.Errorf("expected origin %v, actual %v", origin, actual)
+	}
+	if _, ok := entry.Origin("web.local"); ok {
+		t.Errorf("expected no origin of 'web.local'")
+	}
+	if _, ok := entry.Origin("unknown.local"); ok {
+		t.Errorf("expected no origin of an unknown host name")
+	}
+
+	clone, _ := CloneEntry(entry)
+	if actual, ok := clone.Origin("api.local"); !ok || actual != origin {
+		t.Errorf("expected clone to keep origin %v, actual %v", origin, actual)
+	}
+
+	entry.RemoveHostName("api.local")
+	entry.AddHostName("api.local")
+	if _, ok := entry.Origin("api.local"); ok {
+		t.Errorf("expected origin to be removed together with the host name")
+	}
+}
+
+func TestEntrySet_AddEntryKeepsFirstOrigin(t *testing.T) {
+	first := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"})
+	first.SetOrigin("api.local", Origin{Source: "first", Line: 1})
+	second := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"})
+	second.SetOrigin("api.local", Origin{Source: "second", Line: 1})
+	second.SetOrigin("web.local", Origin{Source: "second", Line: 1})
+
+	entries := NewEntrySet()
+	entries.AddEntry(first, second)
+
+	entry := entries.AllEntries()[0]
+	if origin, _ := entry.Origin("api.local"); origin.Source != "first" {
+		t.Errorf("expected first origin of 'api.local' to be kept, actual %v", origin)
+	}
+	if origin, _ := entry.Origin("web.local"); origin.Source != "second" {
+		t.Errorf("expected origin of 'web.local' from second entry, actual %v", origin)
+	}
+}
+
+func TestConflictWithOrigins(t *testing.T) {
+	first := NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"})
+	first.SetOrigin("api.local", Origin{Source: "docker", Line: 2})
+	second := NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"api.local"})
+
+	entries := NewEntrySet()
+	entries.AddEntry(first, second)
+
+	conflicts := FindConflicts(entries)
+	expected := "api.local => 10.0.0.1 from docker (line 2), 10.0.0.2"
+	if len(conflicts) != 1 || conflicts[0].String() != expected {
+		t.Errorf("expected conflict '%s', actual %v", expected, conflicts)
+	}
+}
@@ -0,0 +1,181 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// A Layer is one entry set of an overlay together with the host names it hides in all lower layers.
+type Layer struct {
+	EntrySet   ReadOnlyEntrySet
+	Tombstones []string
+}
+
+type overlay struct {
+	layers []Layer
+}
+
+// NewOverlay returns a ReadOnlyEntrySet over the given layers, ordered from the highest to the lowest precedence
+// (e.g. runtime overrides, user file, system file). Every host name is resolved from the highest layer
+// which defines it, a tombstone hides the host name in all lower layers. The layers are resolved on every call,
+// so changes of the underlying sets are visible immediately. Lookups resolve only the given host name or ip,
+// AllEntries resolves all layers.
+func NewOverlay(layers ...Layer) ReadOnlyEntrySet {
+	return &overlay{layers: layers}
+}
+
+// resolve returns an EntrySet of all visible host names.
+func (o *overlay) resolve() EntrySet {
+	resolved := NewEntrySet()
+	decided := make(map[string]bool)
+	for _, layer := range o.layers {
+		defined := make(map[string]bool)
+		for _, entry := range layer.EntrySet.AllEntries() {
+			visible := NewEntryUnsafe(entry.Ip(), nil)
+			for _, hostName := range entry.HostNames() {
+				if decided[hostName] {
+					continue
+				}
+				defined[hostName] = true
+				_ = visible.AddHostName(hostName)
+			}
+			if len(visible.HostNames()) > 0 {
+				visible.SetComment(entry.Comment())
+				copyOrigins(visible, entry)
+				resolved.AddEntry(visible)
+			}
+		}
+
+		for hostName := range defined {
+			decided[hostName] = true
+		}
+		for _, hostName := range layer.Tombstones {
+			decided[strings.ToLower(hostName)] = true
+		}
+	}
+	return resolved
+}
+
+// lookupHost resolves the given lower case host name from the highest layer which defines or hides it,
+// layer is the index of that layer or -1 if no layer knows the host name.
+func (o *overlay) lookupHost(hostName string) (ips []net.IP, layer int, ok bool) {
+	for i, l := range o.layers {
+		if ips, ok := l.EntrySet.LookupHost(hostName); ok {
+			return ips, i, true
+		}
+		if l.hides(hostName) {
+			return nil, i, false
+		}
+	}
+	return nil, -1, false
+}
+
+// hides reports whether the given lower case host name is a tombstone of the layer.
+func (l Layer) hides(hostName string) bool {
+	for _, tombstone := range l.Tombstones {
+		if strings.ToLower(tombstone) == hostName {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *overlay) Contains(entry Entry) bool {
+	hostNames := entry.HostNames()
+	if len(hostNames) == 0 {
+		_, ok := o.EntriesOfIP(entry.Ip())
+		return ok
+	}
+	for _, hostName := range hostNames {
+		ips, _, ok := o.lookupHost(strings.ToLower(hostName))
+		if !ok || !containsIP(ips, entry.Ip()) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// EntriesOfIP returns the host names of the given ip, which are resolved from the layer defining them for this ip.
+// The ip is resolved once per layer, a layer is only scanned for the host names it hides in lower layers
+// if a lower layer maps the ip to a host name which is not decided yet.
+func (o *overlay) EntriesOfIP(ip net.IP) (hosts []string, ok bool) {
+	candidates := make([][]string, len(o.layers))
+	for i, layer := range o.layers {
+		candidates[i], _ = layer.EntrySet.EntriesOfIP(ip)
+	}
+
+	decided := make(map[string]bool)
+	for i, layer := range o.layers {
+		for _, hostName := range candidates[i] {
+			if !decided[hostName] {
+				decided[hostName] = true
+				hosts = append(hosts, hostName)
+			}
+		}
+
+		pending := make(map[string]bool)
+		for _, lower := range candidates[i+1:] {
+			for _, hostName := range lower {
+				if !decided[hostName] {
+					pending[hostName] = true
+				}
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		for _, hostName := range layer.Tombstones {
+			decided[strings.ToLower(hostName)] = true
+		}
+		for _, entry := range layer.EntrySet.AllEntries() {
+			for _, hostName := range entry.HostNames() {
+				if pending[hostName] {
+					decided[hostName] = true
+				}
+			}
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, len(hosts) > 0
+}
+
+func (o *overlay) LookupHost(hostName string) (ips []net.IP, ok bool) {
+	ips, _, ok = o.lookupHost(strings.ToLower(hostName))
+	return ips, ok
+}
+
+func (o *overlay) AllEntries() []Entry {
+	return o.resolve().AllEntries()
+}
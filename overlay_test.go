@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"testing"
+)
+
+func newOverlayTestLayers() (runtime, user, system EntrySet) {
+	runtime = NewEntrySet()
+	runtime.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.99"), []string{"api.local"}))
+
+	user = NewEntrySet()
+	user.AddEntry(
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}),
+		NewEntryUnsafe(net.ParseIP("::1"), []string{"db.local"}))
+
+	system = NewEntrySet()
+	system.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost", "db.local", "ads.example.com"}),
+		NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost"}))
+	return runtime, user, system
+}
+
+func TestOverlay_LookupHost(t *testing.T) {
+	runtime, user, system := newOverlayTestLayers()
+	overlay := NewOverlay(
+		Layer{EntrySet: runtime},
+		Layer{EntrySet: user, Tombstones: []string{"ADS.example.com"}},
+		Layer{EntrySet: system})
+
+	tests := []struct {
+		hostName string
+		ips      []string
+	}{
+		{hostName: "api.local", ips: []string{"10.0.0.99"}},
+		{hostName: "web.local", ips: []string{"10.0.0.1"}},
+		{hostName: "db.local", ips: []string{"::1"}},
+		{hostName: "localhost", ips: []string{"127.0.0.1", "::1"}},
+		{hostName: "ads.example.com", ips: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.hostName, func(t *testing.T) {
+			ips, ok := overlay.LookupHost(test.hostName)
+			if ok != (len(test.ips) > 0) || len(ips) != len(test.ips) {
+				t.Fatalf("expected ips %v, actual %v", test.ips, ips)
+			}
+			for i, ip := range ips {
+				if ip.String() != test.ips[i] {
+					t.Errorf("expected ips %v, actual %v", test.ips, ips)
+				}
+			}
+		})
+	}
+}
+
+func TestOverlay_EntriesOfIP(t *testing.T) {
+	runtime, user, system := newOverlayTestLayers()
+	overlay := NewOverlay(Layer{EntrySet: runtime}, Layer{EntrySet: user}, Layer{EntrySet: system})
+
+	hostNames, ok := overlay.EntriesOfIP(net.ParseIP("127.0.0.1"))
+	if !ok || len(hostNames) != 2 || hostNames[0] != "ads.example.com" || hostNames[1] != "localhost" {
+		t.Errorf("unexpected host names %v of 127.0.0.1", hostNames)
+	}
+
+	hidden := NewOverlay(Layer{EntrySet: user, Tombstones: []string{"ADS.example.com"}}, Layer{EntrySet: system})
+	hostNames, ok = hidden.EntriesOfIP(net.ParseIP("127.0.0.1"))
+	if !ok || len(hostNames) != 1 || hostNames[0] != "localhost" {
+		t.Errorf("unexpected host names %v of 127.0.0.1 with tombstones", hostNames)
+	}
+
+	if _, ok := overlay.EntriesOfIP(net.ParseIP("10.0.0.2")); ok {
+		t.Errorf("expected no host names of 10.0.0.2")
+	}
+
+	if !overlay.Contains(NewEntryUnsafe(net.ParseIP("::1"), []string{"db.local", "localhost"})) {
+		t.Errorf("expected overlay to contain the merged ::1 entry")
+	}
+	if overlay.Contains(NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local"})) {
+		t.Errorf("expected 'api.local' of the user layer to be shadowed")
+	}
+
+	if entries := overlay.AllEntries(); len(entries) != 4 {
+		t.Errorf("expected 4 entries, actual %v", entries)
+	}
+}
+
+func TestOverlay_SeesChangesOfLayers(t *testing.T) {
+	runtime, user, system := newOverlayTestLayers()
+	overlay := NewOverlay(Layer{EntrySet: runtime}, Layer{EntrySet: user}, Layer{EntrySet: system})
+
+	runtime.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.98"), []string{"web.local"}))
+
+	ips, _ := overlay.LookupHost("web.local")
+	if len(ips) != 1 || ips[0].String() != "10.0.0.98" {
+		t.Errorf("expected the new runtime mapping, actual %v", ips)
+	}
+}
+
+func TestOverlay_ReadOnly(t *testing.T) {
+	if _, ok := NewOverlay().(EntrySet); ok {
+		t.Errorf("expected a read-only entry set")
+	}
+}
+
+// countingEntrySet counts the calls of AllEntries.
+type countingEntrySet struct {
+	EntrySet
+	allEntries int
+}
+
+func (c *countingEntrySet) AllEntries() []Entry {
+	c.allEntries++
+	return c.EntrySet.AllEntries()
+}
+
+func TestOverlay_LookupsResolveOnlyTheQuery(t *testing.T) {
+	runtime, user, system := newOverlayTestLayers()
+	layers := []*countingEntrySet{{EntrySet: runtime}, {EntrySet: user}, {EntrySet: system}}
+	overlay := NewOverlay(Layer{EntrySet: layers[0]}, Layer{EntrySet: layers[1]}, Layer{EntrySet: layers[2]})
+
+	overlay.LookupHost("localhost")
+	overlay.Contains(NewEntryUnsafe(net.ParseIP("::1"), []string{"db.local"}))
+
+	for i, layer := range layers {
+		if layer.allEntries != 0 {
+			t.Errorf("expected no AllEntries call of layer %d, actual %d", i, layer.allEntries)
+		}
+	}
+
+	// the host names of an ip are resolved with at most one scan per layer
+	overlay.EntriesOfIP(net.ParseIP("127.0.0.1"))
+	for i, layer := range layers {
+		if layer.allEntries > 1 {
+			t.Errorf("expected at most one AllEntries call of layer %d, actual %d", i, layer.allEntries)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+var fixtures = []string{"system", "blocklist"}
+
+func BenchmarkReadFromLine(b *testing.B) {
+	lines := map[string]string{
+		"entry":   "0.0.0.0 ads1234.tracking-metrics.example.com",
+		"aliases": "  10.0.0.5\tapi.local  api  web.local\t",
+		"comment": "10.0.0.5 api.local # payments api owner=payments ticket=OPS-12",
+		"ignored": "# a plain comment line",
+	}
+	for name, line := range lines {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = ReadFromLine(line)
+			}
+		})
+		b.Run(name+"/regexp", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = readFromLineRegexp(line)
+			}
+		})
+	}
+}
+
+func BenchmarkTrimWhitespace(b *testing.B) {
+	line := " \t0.0.0.0 ads1234.tracking-metrics.example.com  "
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = TrimWhitespace(line)
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, fixture := range fixtures {
+		content := readFixture(fixture, b)
+		b.Run(fixture, func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Read(bytes.NewReader(content)); err != nil {
+					b.Fatalf("unexpected error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadDocument(b *testing.B) {
+	for _, fixture := range fixtures {
+		content := readFixture(fixture, b)
+		b.Run(fixture, func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ReadDocument(bytes.NewReader(content)); err != nil {
+					b.Fatalf("unexpected error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func readFixture(name string, b *testing.B) []byte {
+	content, err := ioutil.ReadFile("testdata/" + name + ".hosts")
+	if err != nil {
+		b.Fatalf("unexpected error %v", err)
+	}
+	return content
+}
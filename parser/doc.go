@@ -41,5 +41,17 @@ Package parser provides an interface to parse line/file-entry from/to hosts-file
 
   err := Write(entrySet, writer)
 
+To keep comments, blank lines and disabled (commented-out) entries, read the file as a Document:
+
+  doc, err := ReadDocument(reader)
+
+  // ...
+
+  err = doc.Enable("api.local")
+
+  // ...
+
+  _, err = doc.WriteTo(writer)
+
 */
 package parser
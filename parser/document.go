@@ -0,0 +1,377 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"errors"
+	"github.com/bitofcode/hosts"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	HostNameNotFoundError = errors.New("host name not found")
+	TagNotFoundError      = errors.New("tag not found")
+)
+
+// A Line is a single line of a hosts file as it was read, together with its parsed entry (if any).
+type Line struct {
+	number   int
+	raw      string
+	entry    hosts.Entry
+	disabled bool
+}
+
+// Number returns the 1-based position of the line in the source it was read from (0 for added lines).
+func (l *Line) Number() int {
+	return l.number
+}
+
+// Raw returns the text of the line without line-separator.
+func (l *Line) Raw() string {
+	return l.raw
+}
+
+// Entry returns the entry of the line or nil if the line is blank or a plain comment.
+func (l *Line) Entry() hosts.Entry {
+	return l.entry
+}
+
+// Disabled reports whether the line is a commented-out entry, e.g. '# 10.0.0.5 api.local'.
+func (l *Line) Disabled() bool {
+	return l.disabled
+}
+
+// Update renders the line again from its entry, e.g. after its comment was changed.
+// Lines without entry are left untouched.
+func (l *Line) Update() error {
+	if l.entry == nil {
+		return nil
+	}
+	raw, err := WriteToLine(l.entry)
+	if err != nil {
+		return err
+	}
+	if l.disabled {
+		raw = commentSign + " " + raw
+	}
+	l.raw = raw
+	return nil
+}
+
+func (l *Line) disable() {
+	if l.entry == nil || l.disabled {
+		return
+	}
+	l.raw = commentSign + " " + l.raw
+	l.disabled = true
+}
+
+func (l *Line) enable() {
+	if l.entry == nil || !l.disabled {
+		return
+	}
+	l.raw = uncomment(l.raw)
+	l.disabled = false
+}
+
+// A Document is the line-by-line model of a hosts file, which keeps comments, blank lines and
+// disabled entries, so it can be written back without losing anything.
+type Document struct {
+	lines      []*Line
+	operations []Operation
+}
+
+// NewDocument returns an empty Document.
+func NewDocument() *Document {
+	return &Document{lines: make([]*Line, 0)}
+}
+
+// ReadDocument reads the hosts file from the provided io.Reader and returns its Document.
+func ReadDocument(reader io.Reader) (*Document, error) {
+	return ReadDocumentWithOrigin(reader, hosts.Origin{})
+}
+
+// ReadDocumentWithOrigin reads the hosts file like ReadDocument and records the given origin
+// together with the line number for every host name.
+func ReadDocumentWithOrigin(reader io.Reader, origin hosts.Origin) (*Document, error) {
+	doc := NewDocument()
+	scanner := NewScannerWithOrigin(reader, origin)
+	for scanner.Scan() {
+		doc.lines = append(doc.lines, scanner.Line())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, unwrapLineError(err)
+	}
+	return doc, nil
+}
+
+func readLine(raw string) (*Line, error) {
+	line := &Line{raw: raw}
+	entry, err := ReadFromLine(raw)
+	if err == InvalidLineError {
+		return nil, err
+	}
+	if err == nil {
+		line.entry = entry
+		return line, nil
+	}
+
+	entry, err = readDisabledFromLine(raw)
+	if err == nil {
+		line.entry = entry
+		line.disabled = true
+	}
+	return line, nil
+}
+
+// readDisabledFromLine parses a line which is commented out by exactly one comment sign.
+func readDisabledFromLine(raw string) (hosts.Entry, error) {
+	trimmedLine := TrimWhitespace(raw)
+	if !strings.HasPrefix(trimmedLine, commentSign) {
+		return nil, emptyLineError
+	}
+	return ReadFromLine(trimmedLine[len(commentSign):])
+}
+
+// uncomment removes the leading comment sign and one following space of the given line, keeping its indentation.
+func uncomment(raw string) string {
+	position := strings.Index(raw, commentSign)
+	return raw[:position] + strings.TrimPrefix(raw[position+len(commentSign):], " ")
+}
+
+// Lines returns all lines of the document in order.
+func (d *Document) Lines() []*Line {
+	lines := make([]*Line, len(d.lines))
+	copy(lines, d.lines)
+	return lines
+}
+
+// Entries returns the entries of all enabled lines in order.
+func (d *Document) Entries() []hosts.Entry {
+	return d.entries(false)
+}
+
+// DisabledEntries returns the entries of all disabled lines in order.
+func (d *Document) DisabledEntries() []hosts.Entry {
+	return d.entries(true)
+}
+
+func (d *Document) entries(disabled bool) []hosts.Entry {
+	entries := make([]hosts.Entry, 0)
+	for _, line := range d.lines {
+		if line.entry != nil && line.disabled == disabled {
+			entries = append(entries, line.entry)
+		}
+	}
+	return entries
+}
+
+// EntrySet returns a hosts.EntrySet of all enabled entries.
+func (d *Document) EntrySet() hosts.EntrySet {
+	entrySet := hosts.NewEntrySet()
+	for _, entry := range d.Entries() {
+		entrySet.AddEntry(entry)
+	}
+	return entrySet
+}
+
+// Enable enables every disabled line which contains the given host name by removing its leading comment sign.
+// The whole line is enabled, including other host names on the same line.
+func (d *Document) Enable(hostName string) error {
+	return d.toggle(hostName, true)
+}
+
+// Disable disables every enabled line which contains the given host name by prefixing it with a comment sign.
+// The whole line is disabled, including other host names on the same line.
+func (d *Document) Disable(hostName string) error {
+	return d.toggle(hostName, false)
+}
+
+func (d *Document) toggle(hostName string, enable bool) error {
+	defer d.record(toggleDescription(enable)+" "+hostName, d.snapshot())
+	hostName = strings.ToLower(hostName)
+	found := d.toggleLines(func(entry hosts.Entry) bool { return entry.Contains(hostName) }, enable)
+	if !found {
+		return HostNameNotFoundError
+	}
+	return nil
+}
+
+// EnableTag enables every disabled line whose entry is tagged with the given tag.
+func (d *Document) EnableTag(tag string) error {
+	return d.toggleTag(tag, true)
+}
+
+// DisableTag disables every enabled line whose entry is tagged with the given tag.
+func (d *Document) DisableTag(tag string) error {
+	return d.toggleTag(tag, false)
+}
+
+func (d *Document) toggleTag(tag string, enable bool) error {
+	defer d.record(toggleDescription(enable)+" tag "+tag, d.snapshot())
+	found := d.toggleLines(func(entry hosts.Entry) bool { return entry.HasTag(tag) }, enable)
+	if !found {
+		return TagNotFoundError
+	}
+	return nil
+}
+
+func toggleDescription(enable bool) string {
+	if enable {
+		return "enable"
+	}
+	return "disable"
+}
+
+func (d *Document) toggleLines(matches func(entry hosts.Entry) bool, enable bool) (found bool) {
+	for _, line := range d.lines {
+		if line.entry == nil || !matches(line.entry) {
+			continue
+		}
+		found = true
+		if enable {
+			line.enable()
+		} else {
+			line.disable()
+		}
+	}
+	return found
+}
+
+// Remove removes the given host name from every line (enabled or disabled),
+// lines without any other host name are removed completely.
+func (d *Document) Remove(hostName string) error {
+	defer d.record("remove "+hostName, d.snapshot())
+	hostName = strings.ToLower(hostName)
+	found := false
+	var err error
+	d.removeLines(func(line *Line) bool {
+		if line.entry == nil || !line.entry.Contains(hostName) {
+			return false
+		}
+		found = true
+		line.entry.RemoveHostName(hostName)
+		if len(line.entry.HostNames()) == 0 {
+			return true
+		}
+		if updateErr := line.Update(); updateErr != nil {
+			err = updateErr
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return HostNameNotFoundError
+	}
+	return nil
+}
+
+// RemoveTag removes every line (enabled or disabled) whose entry is tagged with the given tag.
+func (d *Document) RemoveTag(tag string) error {
+	defer d.record("remove tag "+tag, d.snapshot())
+	found := false
+	d.removeLines(func(line *Line) bool {
+		if line.entry != nil && line.entry.HasTag(tag) {
+			found = true
+			return true
+		}
+		return false
+	})
+	if !found {
+		return TagNotFoundError
+	}
+	return nil
+}
+
+// Add appends a new enabled line for the given entry and returns it.
+func (d *Document) Add(entry hosts.Entry) (*Line, error) {
+	raw, err := WriteToLine(entry)
+	if err != nil {
+		return nil, err
+	}
+	line := &Line{raw: raw, entry: entry}
+	defer d.record("add "+entry.Ip().String()+" "+strings.Join(entry.HostNames(), " "), d.snapshot())
+	d.lines = append(d.lines, line)
+	return line, nil
+}
+
+// Expire removes every line (enabled or disabled) whose entry is expired at the given time
+// and returns the removed entries.
+func (d *Document) Expire(now time.Time) []hosts.Entry {
+	expired := make([]hosts.Entry, 0)
+	defer d.record("expire", d.snapshot())
+	d.removeLines(func(line *Line) bool {
+		if line.entry != nil && line.entry.Expired(now) {
+			expired = append(expired, line.entry)
+			return true
+		}
+		return false
+	})
+	return expired
+}
+
+// NextExpiry returns the earliest expiry time of all entries in the document.
+func (d *Document) NextExpiry() (expiry time.Time, ok bool) {
+	for _, line := range d.lines {
+		if line.entry == nil {
+			continue
+		}
+		if lineExpiry, lineOk := line.entry.ExpiresAt(); lineOk && (!ok || lineExpiry.Before(expiry)) {
+			expiry, ok = lineExpiry, true
+		}
+	}
+	return expiry, ok
+}
+
+func (d *Document) removeLines(remove func(line *Line) bool) {
+	kept := make([]*Line, 0, len(d.lines))
+	for _, line := range d.lines {
+		if !remove(line) {
+			kept = append(kept, line)
+		}
+	}
+	d.lines = kept
+}
+
+// WriteTo writes the document line by line into the given io.Writer.
+func (d *Document) WriteTo(writer io.Writer) (n int64, err error) {
+	for _, line := range d.lines {
+		written, err := io.WriteString(writer, line.raw+"\n")
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// String returns the content of the document.
+func (d *Document) String() string {
+	builder := &strings.Builder{}
+	_, _ = d.WriteTo(builder)
+	return builder.String()
+}
@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"net"
+	"testing"
+	"time"
+)
+
+const documentContent = `127.0.0.1  localhost
+# a comment
+# 10.0.0.5 api.local
+  #10.0.0.6   web.local # staging
+
+## 10.0.0.7 old.local
+192.168.10.10 example.com
+`
+
+func TestReadDocument(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	if len(doc.Lines()) != 7 {
+		t.Fatalf("expected 7 lines, actual %d", len(doc.Lines()))
+	}
+
+	entries := doc.Entries()
+	if len(entries) != 2 || !entries[0].Contains("localhost") || !entries[1].Contains("example.com") {
+		t.Errorf("unexpected enabled entries %v", entries)
+	}
+
+	disabled := doc.DisabledEntries()
+	if len(disabled) != 2 || !disabled[0].Contains("api.local") || !disabled[1].Contains("web.local") {
+		t.Errorf("unexpected disabled entries %v", disabled)
+	}
+
+	if doc.String() != documentContent {
+		t.Errorf("expected '%s' actual '%s'", documentContent, doc.String())
+	}
+}
+
+func TestReadDocumentInvalidLine(t *testing.T) {
+	_, err := ReadDocument(bytes.NewBufferString("127.0.0.1 localhost\n123\n"))
+	if err != InvalidLineError {
+		t.Errorf("expected error '%v', actual '%v'", InvalidLineError, err)
+	}
+}
+
+func TestDocument_EntrySet(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	entrySet := doc.EntrySet()
+	if len(entrySet.AllEntries()) != 2 {
+		t.Errorf("expected only enabled entries in %v", entrySet)
+	}
+}
+
+func TestDocument_EnableDisable(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	assertNoError(doc.Enable("api.local"), t)
+	assertNoError(doc.Enable("WEB.local"), t)
+	assertNoError(doc.Disable("example.com"), t)
+
+	expected := `127.0.0.1  localhost
+# a comment
+10.0.0.5 api.local
+  10.0.0.6   web.local # staging
+
+## 10.0.0.7 old.local
+# 192.168.10.10 example.com
+`
+	if doc.String() != expected {
+		t.Fatalf("expected '%s' actual '%s'", expected, doc.String())
+	}
+
+	assertNoError(doc.Disable("api.local"), t)
+	assertNoError(doc.Disable("web.local"), t)
+	assertNoError(doc.Enable("example.com"), t)
+
+	expected = `127.0.0.1  localhost
+# a comment
+# 10.0.0.5 api.local
+#   10.0.0.6   web.local # staging
+
+## 10.0.0.7 old.local
+192.168.10.10 example.com
+`
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+}
+
+func TestDocument_EnableUnknownHostName(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	tests := []string{"unknown.local", "old.local", "a"}
+	for _, hostName := range tests {
+		t.Run(hostName, func(t *testing.T) {
+			if err := doc.Enable(hostName); err != HostNameNotFoundError {
+				t.Errorf("expected error '%v', actual '%v'", HostNameNotFoundError, err)
+			}
+		})
+	}
+}
+
+func TestLine_Update(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	for _, line := range doc.Lines() {
+		if line.Entry() != nil && line.Entry().Contains("web.local") {
+			assertNoError(line.Entry().SetMetadata("owner", "payments"), t)
+			assertNoError(line.Update(), t)
+		}
+	}
+
+	expected := "# 10.0.0.6  web.local  # staging owner=payments"
+	if doc.Lines()[3].Raw() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.Lines()[3].Raw())
+	}
+}
+
+func TestDocument_Add(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString("127.0.0.1 localhost\n"))
+	assertNoError(err, t)
+
+	entry := hosts.NewEntryUnsafe(net.ParseIP("10.1.2.3"), []string{"api.prod"})
+	entry.SetExpiresAt(time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC))
+	line, err := doc.Add(entry)
+	assertNoError(err, t)
+
+	if line.Disabled() || line.Entry() != entry {
+		t.Errorf("unexpected line %v", line)
+	}
+
+	expected := "127.0.0.1 localhost\n10.1.2.3  api.prod  # expires=2020-05-01T12:00:00Z\n"
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+}
+
+func TestDocument_Expire(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(`127.0.0.1 localhost
+10.1.2.3 api.prod # expires=2020-05-01T12:00:00Z
+# 10.1.2.4 web.prod # expires=2020-05-01T10:00:00Z
+10.1.2.5 db.prod # expires=2020-05-01T14:00:00Z
+`))
+	assertNoError(err, t)
+
+	next, ok := doc.NextExpiry()
+	if !ok || !next.Equal(time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next expiry %v", next)
+	}
+
+	expired := doc.Expire(time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC))
+	if len(expired) != 2 {
+		t.Errorf("expected 2 expired entries, actual %v", expired)
+	}
+
+	expected := "127.0.0.1 localhost\n10.1.2.5 db.prod # expires=2020-05-01T14:00:00Z\n"
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+}
+
+const taggedContent = `127.0.0.1 localhost
+10.0.0.1 api.staging # tags=billing,staging
+# 10.0.0.2 web.staging # tags=staging
+10.0.0.3 api.dev www.dev # tags=dev
+`
+
+func TestDocument_EnableDisableTag(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(taggedContent))
+	assertNoError(err, t)
+
+	assertNoError(doc.EnableTag("staging"), t)
+	assertNoError(doc.DisableTag("dev"), t)
+
+	expected := `127.0.0.1 localhost
+10.0.0.1 api.staging # tags=billing,staging
+10.0.0.2 web.staging # tags=staging
+# 10.0.0.3 api.dev www.dev # tags=dev
+`
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+
+	if err := doc.DisableTag("unknown"); err != TagNotFoundError {
+		t.Errorf("expected error '%v', actual '%v'", TagNotFoundError, err)
+	}
+}
+
+func TestDocument_RemoveTag(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(taggedContent))
+	assertNoError(err, t)
+
+	assertNoError(doc.RemoveTag("staging"), t)
+
+	expected := "127.0.0.1 localhost\n10.0.0.3 api.dev www.dev # tags=dev\n"
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+
+	if err := doc.RemoveTag("staging"); err != TagNotFoundError {
+		t.Errorf("expected error '%v', actual '%v'", TagNotFoundError, err)
+	}
+}
+
+func TestDocument_Remove(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(taggedContent))
+	assertNoError(err, t)
+
+	assertNoError(doc.Remove("www.dev"), t)
+	assertNoError(doc.Remove("web.staging"), t)
+
+	expected := "127.0.0.1 localhost\n10.0.0.1 api.staging # tags=billing,staging\n10.0.0.3  api.dev  # tags=dev\n"
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+
+	if err := doc.Remove("www.dev"); err != HostNameNotFoundError {
+		t.Errorf("expected error '%v', actual '%v'", HostNameNotFoundError, err)
+	}
+}
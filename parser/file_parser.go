@@ -23,7 +23,6 @@
 package parser
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/bitofcode/hosts"
 	"io"
@@ -32,31 +31,44 @@ import (
 
 // Read reads the hosts file from the provided io.Reader and returns an EntrySet.
 func Read(reader io.Reader) (entrySet hosts.EntrySet, err error) {
+	return ReadWithOrigin(reader, hosts.Origin{})
+}
+
+// ReadWithOrigin reads the hosts file like Read and records the given origin
+// together with the line number for every host name.
+func ReadWithOrigin(reader io.Reader, origin hosts.Origin) (entrySet hosts.EntrySet, err error) {
 	entrySet = hosts.NewEntrySet()
-	scanner := bufio.NewScanner(reader)
-	lineNumber := 0
+	scanner := NewScannerWithOrigin(reader, origin)
 	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-		entry, err := ReadFromLine(line)
-		if err != nil && err == InvalidLineError {
-			return nil, err
-		}
-		if err == nil {
-			entrySet.AddEntry(entry)
+		if line := scanner.Line(); line.entry != nil && !line.disabled {
+			entrySet.AddEntry(line.entry)
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, unwrapLineError(err)
+	}
 	return entrySet, nil
 }
 
+func setOrigin(entry hosts.Entry, origin hosts.Origin, lineNumber int) {
+	origin.Line = lineNumber
+	for _, hostName := range entry.HostNames() {
+		entry.SetOrigin(hostName, origin)
+	}
+}
+
 // Write writes the EntrySet to a well formatted hosts file into io.Write.
 func Write(entrySet hosts.EntrySet, writer io.Writer) error {
 	return WriteWith(entrySet, writer, WriteToLine)
 }
 
 // WriteWith writes all entries formatted with the provided formatter from the provided EntrySet into io.Write.
+// The entries are ordered by their ips with hosts.CompareIPs, so loopback entries come first.
 func WriteWith(entrySet hosts.EntrySet, writer io.Writer, formatter func(ent hosts.Entry) (line string, err error)) error {
 	entries := entrySet.AllEntries()
+	sort.Slice(entries, func(i, j int) bool {
+		return hosts.CompareIPs(entries[i].Ip(), entries[j].Ip()) < 0
+	})
 
 	lines := make([]string, 0)
 	for _, entry := range entries {
@@ -67,7 +79,6 @@ func WriteWith(entrySet hosts.EntrySet, writer io.Writer, formatter func(ent hos
 		lines = append(lines, line)
 	}
 
-	sort.Strings(lines)
 	for _, l := range lines {
 		_, err := io.WriteString(writer, fmt.Sprintln(l))
 		if err != nil {
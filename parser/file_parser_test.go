@@ -128,6 +128,9 @@ func TestWriteFile(t *testing.T) {
 
 func getExpectedContent(entries []hosts.Entry, t *testing.T) string {
 	expectedBuffer := bytes.NewBuffer(make([]byte, 0))
+	sort.Slice(entries, func(i, j int) bool {
+		return hosts.CompareIPs(entries[i].Ip(), entries[j].Ip()) < 0
+	})
 	lines := make([]string, 0)
 	for _, entry := range entries {
 		line, err := WriteToLine(entry)
@@ -137,7 +140,6 @@ func getExpectedContent(entries []hosts.Entry, t *testing.T) string {
 		lines = append(lines, line)
 	}
 
-	sort.Strings(lines)
 	for _, l := range lines {
 		_, err := io.WriteString(expectedBuffer, fmt.Sprintln(l))
 		if err != nil {
@@ -148,3 +150,27 @@ func getExpectedContent(entries []hosts.Entry, t *testing.T) string {
 	expectedContent := expectedBuffer.String()
 	return expectedContent
 }
+
+func TestReadWithOrigin(t *testing.T) {
+	reader := bytes.NewBufferString("# vpn\n10.8.0.1 vpn.internal\n\n10.8.0.1 git.internal\n")
+
+	entrySet, err := ReadWithOrigin(reader, hosts.Origin{Source: "vpn", Path: "/etc/hosts.d/vpn.hosts"})
+	assertNoError(err, t)
+
+	tests := []struct {
+		hostName string
+		line     int
+	}{
+		{hostName: "vpn.internal", line: 2},
+		{hostName: "git.internal", line: 4},
+	}
+	entry := entrySet.AllEntries()[0]
+	for _, test := range tests {
+		t.Run(test.hostName, func(t *testing.T) {
+			expected := hosts.Origin{Source: "vpn", Path: "/etc/hosts.d/vpn.hosts", Line: test.line}
+			if origin, ok := entry.Origin(test.hostName); !ok || origin != expected {
+				t.Errorf("expected origin %v, actual %v", expected, origin)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DefaultSeparator is the separator between the fields of a line.
+const DefaultSeparator = "  "
+
+// An Order is the order in which WriteFormatted writes the entries.
+type Order int
+
+const (
+	// OrderOriginal keeps the order of the EntrySet, i.e. the order in which the ips were added first.
+	OrderOriginal Order = iota
+	// OrderIP sorts the entries by their ips with hosts.CompareIPs, loopback entries first.
+	OrderIP
+	// OrderHostName sorts the entries by their first host name.
+	OrderHostName
+)
+
+// FormatOptions define the layout of the lines written by WriteFormatted.
+type FormatOptions struct {
+	// Separator is written between the fields of a line, e.g. "\t", it defaults to DefaultSeparator.
+	Separator string
+	// AlignIPs pads all ips with spaces to the width of the longest ip.
+	AlignIPs bool
+	// MaxNamesPerLine splits entries with more host names into several lines of the same ip,
+	// 1 writes one host name per line and 0 all host names on one line.
+	MaxNamesPerLine int
+	Order           Order
+	// IPv4First writes the IPv4 entries before the IPv6 entries, each in the given order.
+	IPv4First bool
+}
+
+// WriteFormatted writes all entries of the EntrySet with the given layout into the io.Writer.
+func WriteFormatted(entrySet hosts.EntrySet, writer io.Writer, options FormatOptions) error {
+	entries := entrySet.AllEntries()
+	sortEntries(entries, options)
+
+	width := 0
+	if options.AlignIPs {
+		for _, entry := range entries {
+			if len(entry.IpString()) > width {
+				width = len(entry.IpString())
+			}
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	for _, entry := range entries {
+		lines, err := formatEntry(entry, options, width)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			buffer.WriteString(line)
+			buffer.WriteString("\n")
+		}
+	}
+	_, err := buffer.WriteTo(writer)
+	return err
+}
+
+// formatEntry returns the lines of the entry, its ip is padded to the given width.
+// Every line repeats the comment, so metadata like the expiry time applies to all host names.
+func formatEntry(entry hosts.Entry, options FormatOptions, width int) ([]string, error) {
+	hostNames := entry.HostNames()
+	if len(hostNames) == 0 {
+		return nil, invalidHostNameList
+	}
+	for _, hostName := range hostNames {
+		if strings.Contains(hostName, commentSign) {
+			return nil, invalidHostName
+		}
+	}
+
+	separator := options.Separator
+	if separator == "" {
+		separator = DefaultSeparator
+	}
+	ip := entry.IpString()
+	if len(ip) < width {
+		ip += strings.Repeat(" ", width-len(ip))
+	}
+	perLine := options.MaxNamesPerLine
+	if perLine <= 0 {
+		perLine = len(hostNames)
+	}
+
+	lines := make([]string, 0, (len(hostNames)+perLine-1)/perLine)
+	for start := 0; start < len(hostNames); start += perLine {
+		end := start + perLine
+		if end > len(hostNames) {
+			end = len(hostNames)
+		}
+		fields := append([]string{ip}, hostNames[start:end]...)
+		if entry.Comment() != "" {
+			fields = append(fields, commentSign+" "+entry.Comment())
+		}
+		lines = append(lines, strings.Join(fields, separator))
+	}
+	return lines, nil
+}
+
+func sortEntries(entries []hosts.Entry, options FormatOptions) {
+	switch options.Order {
+	case OrderIP:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return hosts.CompareIPs(entries[i].Ip(), entries[j].Ip()) < 0
+		})
+	case OrderHostName:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return firstHostName(entries[i]) < firstHostName(entries[j])
+		})
+	}
+	if options.IPv4First {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Ip().To4() != nil && entries[j].Ip().To4() == nil
+		})
+	}
+}
+
+func firstHostName(entry hosts.Entry) string {
+	if hostNames := entry.HostNames(); len(hostNames) > 0 {
+		return hostNames[0]
+	}
+	return ""
+}
+
+// Format rewrites the document in the canonical layout: the fields of entries (enabled or disabled) are separated
+// by DefaultSeparator and followed by their comment, surrounding whitespace is removed from all lines,
+// consecutive blank lines are collapsed and leading and trailing blank lines are removed.
+// The order of lines and host names is kept.
+func (d *Document) Format() {
+	defer d.record("fmt", d.snapshot())
+	lines := make([]*Line, 0, len(d.lines))
+	for _, line := range d.lines {
+		line.raw = canonicalLine(line)
+		if line.raw == "" && (len(lines) == 0 || lines[len(lines)-1].raw == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if last := len(lines) - 1; last >= 0 && lines[last].raw == "" {
+		lines = lines[:last]
+	}
+	d.lines = lines
+}
+
+// canonicalLine returns the raw text of the line in the canonical layout, the fields are kept as they were written.
+func canonicalLine(line *Line) string {
+	raw := strings.TrimSpace(line.raw)
+	if line.entry == nil {
+		return raw
+	}
+
+	prefix := ""
+	if line.disabled {
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, commentSign))
+		prefix = commentSign + " "
+	}
+	formatted := strings.Join(strings.Fields(extractCommentFreeLine(raw)), DefaultSeparator)
+	if comment := extractComment(raw); comment != "" {
+		formatted += DefaultSeparator + commentSign + " " + comment
+	}
+	return prefix + formatted
+}
+
+// Format returns the hosts file src in the canonical layout of Document.Format.
+func Format(src []byte) ([]byte, error) {
+	doc, err := ReadDocument(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	doc.Format()
+	return []byte(doc.String()), nil
+}
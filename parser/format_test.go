@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"net"
+	"testing"
+)
+
+func TestWriteFormatted(t *testing.T) {
+	entrySet := hosts.NewEntrySet()
+	entrySet.AddEntry(
+		hosts.NewEntryUnsafe(net.ParseIP("10.0.0.10"), []string{"web.local", "www.local", "app.local"}),
+		hosts.NewEntryUnsafe(net.ParseIP("::1"), []string{"localhost"}),
+		hosts.NewEntryUnsafe(net.ParseIP("9.9.9.9"), []string{"dns.local"}),
+		hosts.NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	tests := []struct {
+		name     string
+		options  FormatOptions
+		expected string
+	}{
+		{
+			name:    "original order",
+			options: FormatOptions{},
+			expected: "10.0.0.10  app.local  web.local  www.local\n::1  localhost\n9.9.9.9  dns.local\n" +
+				"127.0.0.1  localhost\n",
+		},
+		{
+			name:    "aligned by ip",
+			options: FormatOptions{Order: OrderIP, AlignIPs: true, Separator: "\t", MaxNamesPerLine: 2},
+			expected: "127.0.0.1\tlocalhost\n::1      \tlocalhost\n9.9.9.9  \tdns.local\n" +
+				"10.0.0.10\tapp.local\tweb.local\n10.0.0.10\twww.local\n",
+		},
+		{
+			name:    "by host name, IPv4 first",
+			options: FormatOptions{Order: OrderHostName, IPv4First: true, MaxNamesPerLine: 1},
+			expected: "10.0.0.10  app.local\n10.0.0.10  web.local\n10.0.0.10  www.local\n9.9.9.9  dns.local\n" +
+				"127.0.0.1  localhost\n::1  localhost\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			if err := WriteFormatted(entrySet, buffer, test.options); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if buffer.String() != test.expected {
+				t.Errorf("expected '%s' actual '%s'", test.expected, buffer.String())
+			}
+		})
+	}
+}
+
+func TestWriteFormatted_Comment(t *testing.T) {
+	entry := hosts.NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"a.local", "b.local"})
+	entry.SetComment("expires=2020-05-01T12:00:00Z")
+	entrySet := hosts.NewEntrySet()
+	entrySet.AddEntry(entry)
+
+	buffer := &bytes.Buffer{}
+	if err := WriteFormatted(entrySet, buffer, FormatOptions{MaxNamesPerLine: 1}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := "10.0.0.1  a.local  # expires=2020-05-01T12:00:00Z\n10.0.0.1  b.local  # expires=2020-05-01T12:00:00Z\n"
+	if buffer.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, buffer.String())
+	}
+}
+
+func TestFormat(t *testing.T) {
+	src := "\n\n  # local hosts  \n127.0.0.1\tlocalhost   localhost.localdomain\n\n\n" +
+		"10.0.0.1 web.local web #owner=web\n#10.0.0.2   db.local\n# BEGIN staging\n\n"
+	expected := "# local hosts\n127.0.0.1  localhost  localhost.localdomain\n\n" +
+		"10.0.0.1  web.local  web  # owner=web\n# 10.0.0.2  db.local\n# BEGIN staging\n"
+
+	formatted, err := Format([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(formatted) != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, formatted)
+	}
+
+	again, err := Format(formatted)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if string(again) != expected {
+		t.Errorf("expected the canonical layout to be stable, actual '%s'", again)
+	}
+}
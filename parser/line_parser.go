@@ -29,19 +29,21 @@ import (
 	"fmt"
 	"github.com/bitofcode/hosts"
 	"net"
-	"regexp"
 	"strings"
 )
 
 var (
-	spaceRegexp         = regexp.MustCompile(`\s+`)
 	emptyLineError      = errors.New("empty line")
 	InvalidLineError    = errors.New("invalid line")
 	invalidHostName     = hosts.ErrorInvalidHostName
 	invalidHostNameList = errors.New("invalid host name list")
 )
 
-const commentSign = "#"
+const (
+	commentSign = "#"
+	// maxStackFields is the number of fields of a line which are split without allocation.
+	maxStackFields = 16
+)
 
 // ReadFromLine convert a given string to hostsfile.Entry.
 func ReadFromLine(line string) (ent hosts.Entry, err error) {
@@ -55,7 +57,8 @@ func ReadFromLine(line string) (ent hosts.Entry, err error) {
 		return nil, emptyLineError
 	}
 
-	lineItems := spaceRegexp.Split(commentFreeLine, -1)
+	var buffer [maxStackFields]string
+	lineItems := splitFields(commentFreeLine, buffer[:0])
 	if len(lineItems) <= 1 {
 		return nil, InvalidLineError
 	}
@@ -64,7 +67,12 @@ func ReadFromLine(line string) (ent hosts.Entry, err error) {
 	if ip == nil {
 		return nil, hosts.ErrorInvalidIp
 	}
-	return hosts.NewEntry(ip, lineItems[1:])
+	ent, err = hosts.NewEntry(ip, lineItems[1:])
+	if err != nil {
+		return nil, err
+	}
+	ent.SetComment(extractComment(trimmedLine))
+	return ent, nil
 }
 
 func extractCommentFreeLine(trimmedLine string) string {
@@ -81,6 +89,33 @@ func extractCommentFreeLine(trimmedLine string) string {
 	return TrimWhitespace(commentFreeLine)
 }
 
+// extractComment returns the text behind the first comment sign.
+func extractComment(trimmedLine string) string {
+	commentSignPosition := strings.Index(trimmedLine, commentSign)
+	if commentSignPosition < 0 {
+		return ""
+	}
+	return TrimWhitespace(trimmedLine[commentSignPosition+len(commentSign):])
+}
+
+// splitFields appends the fields of the given trimmed line, separated by runs of whitespaces, to fields.
+func splitFields(line string, fields []string) []string {
+	start := 0
+	for i := 0; i < len(line); i++ {
+		if !isSpace(line[i]) {
+			continue
+		}
+		if start < i {
+			fields = append(fields, line[start:i])
+		}
+		start = i + 1
+	}
+	if start < len(line) {
+		fields = append(fields, line[start:])
+	}
+	return fields
+}
+
 func isEmptyOrComment(line string) bool {
 	return len(line) <= 0 || strings.HasPrefix(line, commentSign)
 }
@@ -97,5 +132,9 @@ func WriteToLine(ent hosts.Entry) (line string, err error) {
 		}
 	}
 
-	return fmt.Sprintf("%s  %s", ent.Ip().String(), strings.Join(ent.HostNames(), "  ")), nil
+	line = fmt.Sprintf("%s  %s", ent.Ip().String(), strings.Join(ent.HostNames(), "  "))
+	if ent.Comment() != "" {
+		line = fmt.Sprintf("%s  %s %s", line, commentSign, ent.Comment())
+	}
+	return line, nil
 }
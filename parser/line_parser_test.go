@@ -23,10 +23,15 @@
 package parser
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/bitofcode/hosts"
 	"net"
+	"os"
+	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -160,6 +165,46 @@ func TestParseFromLineValidLine(t *testing.T) {
 	}
 }
 
+func TestParseFromLineComment(t *testing.T) {
+	tests := []struct {
+		line    string
+		comment string
+	}{
+		{line: "127.0.0.1 localhost", comment: ""},
+		{line: "127.0.0.1 localhost#", comment: ""},
+		{line: "127.0.0.1 localhost # ", comment: ""},
+		{line: "127.0.0.1 localhost#ticket=OPS-12", comment: "ticket=OPS-12"},
+		{line: "127.0.0.1 localhost # owner=payments ticket=OPS-12  ", comment: "owner=payments ticket=OPS-12"},
+		{line: "127.0.0.1 localhost # see #42", comment: "see #42"},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			ent, err := ReadFromLine(test.line)
+			if err != nil {
+				t.Fatalf("For line '%s' an unexpected error occurred: '%v'", test.line, err)
+			}
+			if ent.Comment() != test.comment {
+				t.Errorf("For line '%s', expected comment='%s', actual='%s'", test.line, test.comment, ent.Comment())
+			}
+		})
+	}
+}
+
+func TestParseToLineWithComment(t *testing.T) {
+	ent := hosts.NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"})
+	ent.SetComment("owner=payments")
+
+	gotLine, err := WriteToLine(ent)
+	if err != nil {
+		t.Fatalf("WriteToLine(%v) unexpected error = %v", ent, err)
+	}
+
+	wantLine := "127.0.0.1  localhost  # owner=payments"
+	if gotLine != wantLine {
+		t.Errorf("WriteToLine(%v) want '%v', gotLine = '%v'", ent, wantLine, gotLine)
+	}
+}
+
 func TestParseToLine(t *testing.T) {
 	type args struct {
 		ip        string
@@ -272,3 +317,98 @@ func TestLineRegexp(t *testing.T) {
 		t.Logf("%d => '%s'\n", i, r)
 	}
 }
+
+var (
+	regexpTrimWhitespace = regexp.MustCompile(`^(\s*)(\S+(\s+\S+)*)+(\s*)$`)
+	spaceRegexp          = regexp.MustCompile(`\s+`)
+)
+
+// regexpTrim is the regular expression based TrimWhitespace, which ReadFromLine used before.
+func regexpTrim(oneLine string) string {
+	matches := regexpTrimWhitespace.FindStringSubmatch(oneLine)
+	if matches != nil && len(matches) >= 3 {
+		return matches[2]
+	}
+	return ""
+}
+
+// readFromLineRegexp is the regular expression based ReadFromLine, the reference for the tokenizer.
+func readFromLineRegexp(line string) (hosts.Entry, error) {
+	trimmedLine := regexpTrim(line)
+	if isEmptyOrComment(trimmedLine) {
+		return nil, emptyLineError
+	}
+	commentFreeLine := trimmedLine
+	if position := strings.Index(trimmedLine, commentSign); position >= 0 {
+		commentFreeLine = regexpTrim(trimmedLine[:position])
+	}
+	if isEmptyOrComment(commentFreeLine) {
+		return nil, emptyLineError
+	}
+	lineItems := spaceRegexp.Split(commentFreeLine, -1)
+	if len(lineItems) <= 1 {
+		return nil, InvalidLineError
+	}
+	ip := net.ParseIP(lineItems[0])
+	if ip == nil {
+		return nil, hosts.ErrorInvalidIp
+	}
+	ent, err := hosts.NewEntry(ip, lineItems[1:])
+	if err != nil {
+		return nil, err
+	}
+	if position := strings.Index(trimmedLine, commentSign); position >= 0 {
+		ent.SetComment(regexpTrim(trimmedLine[position+len(commentSign):]))
+	}
+	return ent, nil
+}
+
+func TestReadFromLine_MatchesRegexp(t *testing.T) {
+	lines := []string{
+		"", " ", "\t\r\n", "\v", "#", " # comment", "123", "123 #", "10.0.0.1\f", "\f10.0.0.1\fapi.local\f",
+		"10.0.0.1 api.local", "  10.0.0.1\t\tapi.local  web.local \r", "10.0.0.1 API.Local#comment",
+		"10.0.0.1 api.local # a  b\tc ", "10.0.0.1\vapi.local", "10.0.0.1 api.local\v", "::1 localhost ip6-localhost",
+		"10.0.0.1 ä.local \u00a0x.local", "no-ip api.local", "# 10.0.0.1 api.local", "10.0.0.1 a b c d e f g h i j k l m n o p q r s",
+		"10.0.0.1 \xff\xfe.local", "10.0.0.1 api.local #owner=me  tags=a,b",
+	}
+	for _, name := range []string{"testdata/system.hosts", "testdata/blocklist.hosts"} {
+		lines = append(lines, readLines(name, t)...)
+	}
+
+	for _, line := range lines {
+		if regexpTrim(line) != TrimWhitespace(line) {
+			t.Errorf("line %q: expected trimmed %q, actual %q", line, regexpTrim(line), TrimWhitespace(line))
+		}
+		expected, expectedErr := readFromLineRegexp(line)
+		actual, err := ReadFromLine(line)
+		if err != expectedErr {
+			t.Errorf("line %q: expected error %v, actual %v", line, expectedErr, err)
+			continue
+		}
+		if expected == nil {
+			continue
+		}
+		if !actual.Ip().Equal(expected.Ip()) || !reflect.DeepEqual(actual.HostNames(), expected.HostNames()) ||
+			actual.Comment() != expected.Comment() {
+			t.Errorf("line %q: expected %v # %q, actual %v # %q", line, expected, expected.Comment(), actual, actual.Comment())
+		}
+	}
+}
+
+func readLines(name string, t testing.TB) []string {
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return lines
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"errors"
+	"github.com/bitofcode/hosts/internal/diff"
+)
+
+// OperationConflictError is returned by Apply and Revert if the lines of an operation were changed since.
+var OperationConflictError = errors.New("the lines of the operation were changed since")
+
+// The kinds of a LineChange.
+const (
+	LineAdded   = "add"
+	LineRemoved = "remove"
+)
+
+// An Operation is one logical change of a Document made by one of its mutators, e.g. "add 10.0.0.5 api.local",
+// together with the lines it changed. It can be reverted and applied again on a later version of the document,
+// lines changed by others since are kept.
+type Operation struct {
+	Description string       `json:"description"`
+	Changes     []LineChange `json:"changes"`
+}
+
+// A LineChange adds or removes one line. The changes of an operation are made one after the other.
+type LineChange struct {
+	Kind string `json:"kind"`
+	// Index is the 0-based position of the line in the document at the time of the change.
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	// Anchor is the line before the position of the change, unless the position is the beginning of the document.
+	Anchor  string `json:"anchor"`
+	AtStart bool   `json:"at_start"`
+}
+
+// Operations returns the operations made by the mutators of the document since it was read, the oldest first.
+// Apply and Revert are not recorded.
+func (d *Document) Operations() []Operation {
+	operations := make([]Operation, len(d.operations))
+	copy(operations, d.operations)
+	return operations
+}
+
+// snapshot returns the raw lines of the document.
+func (d *Document) snapshot() []string {
+	raws := make([]string, len(d.lines))
+	for i, line := range d.lines {
+		raws[i] = line.raw
+	}
+	return raws
+}
+
+// record records the change from the raw lines before a mutation to the current lines as operation,
+// nothing is recorded if the mutation did not change the document.
+func (d *Document) record(description string, before []string) {
+	changes := make([]LineChange, 0)
+	index, anchor := 0, ""
+	for _, edit := range diff.Lines(before, d.snapshot()) {
+		switch edit.Op {
+		case diff.Equal:
+			index, anchor = index+1, edit.Text
+		case diff.Delete:
+			changes = append(changes, LineChange{Kind: LineRemoved, Index: index, Text: edit.Text, Anchor: anchor, AtStart: index == 0})
+		case diff.Insert:
+			changes = append(changes, LineChange{Kind: LineAdded, Index: index, Text: edit.Text, Anchor: anchor, AtStart: index == 0})
+			index, anchor = index+1, edit.Text
+		}
+	}
+	if len(changes) > 0 {
+		d.operations = append(d.operations, Operation{Description: description, Changes: changes})
+	}
+}
+
+// Apply makes the changes of the operation again on the current lines of the document. Each line is looked up
+// by its content and the line before it, closest to its former position. If a line cannot be found,
+// OperationConflictError is returned and the document is left unchanged.
+func (d *Document) Apply(operation Operation) error {
+	return d.change(operation.Changes)
+}
+
+// Revert reverts the changes of the operation on the current lines of the document like Apply.
+func (d *Document) Revert(operation Operation) error {
+	inverse := make([]LineChange, len(operation.Changes))
+	for i, change := range operation.Changes {
+		change.Kind = LineAdded
+		if operation.Changes[i].Kind == LineAdded {
+			change.Kind = LineRemoved
+		}
+		inverse[len(inverse)-1-i] = change
+	}
+	return d.change(inverse)
+}
+
+func (d *Document) change(changes []LineChange) error {
+	lines := make([]*Line, len(d.lines))
+	copy(lines, d.lines)
+	for _, change := range changes {
+		position, ok := locate(lines, change)
+		if !ok {
+			return OperationConflictError
+		}
+		if change.Kind == LineRemoved {
+			lines = append(lines[:position], lines[position+1:]...)
+			continue
+		}
+		line, err := readLine(change.Text)
+		if err != nil {
+			return err
+		}
+		lines = append(lines[:position], append([]*Line{line}, lines[position:]...)...)
+	}
+	d.lines = lines
+	return nil
+}
+
+// locate returns the position of the change which is closest to its index. A removed line must have the text of
+// the change, a line is only added after the anchor of the change (or at the beginning of the document).
+func locate(lines []*Line, change LineChange) (int, bool) {
+	best, found := 0, false
+	last := len(lines)
+	if change.Kind == LineRemoved {
+		last--
+	}
+	for position := 0; position <= last; position++ {
+		if !matches(lines, position, change) {
+			continue
+		}
+		if !found || distance(position, change.Index) < distance(best, change.Index) {
+			best, found = position, true
+		}
+	}
+	return best, found
+}
+
+func matches(lines []*Line, position int, change LineChange) bool {
+	if change.Kind == LineRemoved {
+		return lines[position].raw == change.Text
+	}
+	if position == 0 {
+		return change.AtStart
+	}
+	return !change.AtStart && lines[position-1].raw == change.Anchor
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
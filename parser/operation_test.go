@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"net"
+	"testing"
+)
+
+func TestDocument_Operations(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(documentContent))
+	assertNoError(err, t)
+
+	assertNoError(doc.Enable("api.local"), t)
+	_, err = doc.Add(hosts.NewEntryUnsafe(net.ParseIP("10.0.0.8"), []string{"db.local"}))
+	assertNoError(err, t)
+	assertNoError(doc.Remove("example.com"), t)
+	// failed mutations do not record an operation
+	if err := doc.Disable("unknown.local"); err != HostNameNotFoundError {
+		t.Fatalf("expected %v, actual %v", HostNameNotFoundError, err)
+	}
+
+	operations := doc.Operations()
+	descriptions := make([]string, len(operations))
+	for i, operation := range operations {
+		descriptions[i] = operation.Description
+	}
+	if len(operations) != 3 || descriptions[0] != "enable api.local" || descriptions[1] != "add 10.0.0.8 db.local" ||
+		descriptions[2] != "remove example.com" {
+		t.Fatalf("unexpected operations %v", descriptions)
+	}
+	expected := LineChange{Kind: LineRemoved, Index: 2, Text: "# 10.0.0.5 api.local", Anchor: "# a comment"}
+	if len(operations[0].Changes) != 2 || operations[0].Changes[0] != expected {
+		t.Errorf("unexpected changes %v", operations[0].Changes)
+	}
+
+	// revert the operations on a document changed since
+	modified := "# managed by vpn\n" + doc.String()
+	other, err := ReadDocument(bytes.NewBufferString(modified))
+	assertNoError(err, t)
+	for i := len(operations) - 1; i >= 0; i-- {
+		assertNoError(other.Revert(operations[i]), t)
+	}
+	if other.String() != "# managed by vpn\n"+documentContent {
+		t.Errorf("unexpected content %q", other.String())
+	}
+	if len(other.Operations()) != 0 {
+		t.Errorf("expected no operations, actual %v", other.Operations())
+	}
+
+	for _, operation := range operations {
+		assertNoError(other.Apply(operation), t)
+	}
+	if other.String() != modified {
+		t.Errorf("unexpected content %q", other.String())
+	}
+}
+
+func TestDocument_RevertConflict(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString("127.0.0.1 localhost\n"))
+	assertNoError(err, t)
+	_, err = doc.Add(hosts.NewEntryUnsafe(net.ParseIP("10.0.0.8"), []string{"db.local"}))
+	assertNoError(err, t)
+
+	other, err := ReadDocument(bytes.NewBufferString("127.0.0.1 localhost\n10.0.0.9  db.local\n"))
+	assertNoError(err, t)
+	if err := other.Revert(doc.Operations()[0]); err != OperationConflictError {
+		t.Errorf("expected %v, actual %v", OperationConflictError, err)
+	}
+	if other.String() != "127.0.0.1 localhost\n10.0.0.9  db.local\n" {
+		t.Errorf("unexpected content %q", other.String())
+	}
+}
@@ -0,0 +1,163 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"io"
+	"runtime"
+)
+
+// parallelChunkSize is the number of bytes read for every chunk, chunks are extended to the end of their last line.
+const parallelChunkSize = 256 << 10
+
+// A chunk is a part of the input, which ends with a complete line.
+type chunk struct {
+	content []byte
+	// line is the number of the first line of the chunk.
+	line int
+	done chan parsedChunk
+}
+
+type parsedChunk struct {
+	entries []hosts.Entry
+	err     error
+}
+
+// ReadParallel reads the hosts file from the provided io.Reader like Read, but parses chunks of the input
+// concurrently with the given number of workers (runtime.GOMAXPROCS(0) if it is not positive).
+// The entries, their order, their origins and the returned error are the same as for Read.
+func ReadParallel(reader io.Reader, workers int) (hosts.EntrySet, error) {
+	return ReadParallelWithOrigin(reader, hosts.Origin{}, workers)
+}
+
+// ReadParallelWithOrigin reads the hosts file like ReadParallel and records the given origin
+// together with the line number for every host name.
+func ReadParallelWithOrigin(reader io.Reader, origin hosts.Origin, workers int) (hosts.EntrySet, error) {
+	return readParallel(reader, origin, workers, parallelChunkSize)
+}
+
+func readParallel(reader io.Reader, origin hosts.Origin, workers int, chunkSize int) (hosts.EntrySet, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := make(chan *chunk, workers)
+	results := make(chan chan parsedChunk, 2*workers)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go readChunks(reader, chunkSize, chunks, results, stop)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c := range chunks {
+				c.done <- parseChunk(c, origin)
+			}
+		}()
+	}
+
+	// the chunks are merged in the order they were read, so the first occurrence of an ip keeps its position
+	entrySet := hosts.NewEntrySet()
+	for done := range results {
+		parsed := <-done
+		if parsed.err != nil {
+			return nil, unwrapLineError(parsed.err)
+		}
+		for _, entry := range parsed.entries {
+			entrySet.AddEntry(entry)
+		}
+	}
+	return entrySet, nil
+}
+
+// readChunks splits the input into chunks on line boundaries. Every chunk is passed to the workers by chunks
+// and its result channel is passed in the same order by results, a read error is passed as result as well.
+func readChunks(reader io.Reader, chunkSize int, chunks chan<- *chunk, results chan<- chan parsedChunk, stop <-chan struct{}) {
+	defer close(results)
+	defer close(chunks)
+
+	line := 1
+	var rest []byte
+	for {
+		buffer := make([]byte, len(rest), len(rest)+chunkSize)
+		copy(buffer, rest)
+		n, err := io.ReadFull(reader, buffer[len(rest):cap(buffer)])
+		buffer = buffer[:len(rest)+n]
+		end := err == io.EOF || err == io.ErrUnexpectedEOF
+		if end {
+			err = nil
+		}
+
+		cut := len(buffer)
+		if !end {
+			// a line longer than the chunk is read on with the next chunk, the lines before a read error are parsed
+			cut = bytes.LastIndexByte(buffer, '\n') + 1
+		}
+		content := buffer[:cut]
+		rest = buffer[cut:]
+		if len(content) > 0 {
+			c := &chunk{content: content, line: line, done: make(chan parsedChunk, 1)}
+			select {
+			case chunks <- c:
+			case <-stop:
+				return
+			}
+			if !sendResult(results, c.done, stop) {
+				return
+			}
+			line += bytes.Count(content, []byte{'\n'})
+		}
+		if err != nil {
+			done := make(chan parsedChunk, 1)
+			done <- parsedChunk{err: err}
+			sendResult(results, done, stop)
+			return
+		}
+		if end {
+			return
+		}
+	}
+}
+
+// sendResult passes the result channel of a chunk to the merge and reports whether it was not stopped.
+func sendResult(results chan<- chan parsedChunk, done chan parsedChunk, stop <-chan struct{}) bool {
+	select {
+	case results <- done:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// parseChunk returns the enabled entries of the given chunk.
+func parseChunk(c *chunk, origin hosts.Origin) parsedChunk {
+	entries := make([]hosts.Entry, 0)
+	scanner := NewScannerWithOrigin(bytes.NewReader(c.content), origin)
+	scanner.number = c.line - 1
+	for scanner.Scan() {
+		if line := scanner.Line(); line.entry != nil && !line.disabled {
+			entries = append(entries, line.entry)
+		}
+	}
+	return parsedChunk{entries: entries, err: scanner.Err()}
+}
@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeEntries returns the entries of the given set with comments and origins as comparable strings.
+func describeEntries(entrySet hosts.EntrySet) []string {
+	described := make([]string, 0)
+	for _, entry := range entrySet.AllEntries() {
+		origins := make([]string, 0)
+		for _, hostName := range entry.HostNames() {
+			origin, _ := entry.Origin(hostName)
+			origins = append(origins, origin.String())
+		}
+		described = append(described, fmt.Sprintf("%s %v # %s %v", entry.IpString(), entry.HostNames(), entry.Comment(), origins))
+	}
+	return described
+}
+
+func TestReadParallel(t *testing.T) {
+	blocklist, err := ioutil.ReadFile("testdata/blocklist.hosts")
+	assertNoError(err, t)
+	system, err := ioutil.ReadFile("testdata/system.hosts")
+	assertNoError(err, t)
+	long := "10.0.0.1 " + strings.Repeat("x", 1000) + ".local\r\n10.0.0.2 short.local\n10.0.0.1 again.local"
+
+	inputs := map[string]string{
+		"blocklist":  string(blocklist),
+		"system":     string(system),
+		"long line":  long,
+		"empty":      "",
+		"no newline": "10.0.0.1 api.local",
+		"duplicates": strings.Repeat("10.0.0.1 api.local\n10.0.0.2 web.local # owner=web\n10.0.0.1 db.local\n", 10),
+	}
+	origin := hosts.Origin{Source: "test", Path: "/etc/hosts"}
+	for name, input := range inputs {
+		expected, err := ReadWithOrigin(strings.NewReader(input), origin)
+		assertNoError(err, t)
+		for _, chunkSize := range []int{1, 7, 64, 4096, parallelChunkSize} {
+			for _, workers := range []int{1, 4} {
+				t.Run(fmt.Sprintf("%s/%d/%d", name, chunkSize, workers), func(t *testing.T) {
+					actual, err := readParallel(strings.NewReader(input), origin, workers, chunkSize)
+					assertNoError(err, t)
+					if !reflect.DeepEqual(describeEntries(actual), describeEntries(expected)) {
+						t.Errorf("expected the entries of Read")
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestReadParallel_Errors(t *testing.T) {
+	readError := errors.New("device not ready")
+	valid := strings.Repeat("10.0.0.1 api.local\n", 100)
+
+	tests := []struct {
+		name  string
+		input func() io.Reader
+	}{
+		{name: "invalid line", input: func() io.Reader {
+			return strings.NewReader(valid + "10.0.0.1\n" + valid + "no-ip api.local\n")
+		}},
+		{name: "invalid ip", input: func() io.Reader {
+			return strings.NewReader(valid + "no-ip api.local\n" + valid + "10.0.0.1\n")
+		}},
+		{name: "read error", input: func() io.Reader {
+			return &failingReader{reader: strings.NewReader(valid), err: readError}
+		}},
+		{name: "invalid line before read error", input: func() io.Reader {
+			return &failingReader{reader: strings.NewReader(valid + "10.0.0.1\n" + valid), err: readError}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, expected := Read(test.input())
+			if expected == nil {
+				t.Fatalf("expected Read to fail")
+			}
+			for _, chunkSize := range []int{1, 64, parallelChunkSize} {
+				if _, err := readParallel(test.input(), hosts.Origin{}, 4, chunkSize); err != expected {
+					t.Errorf("chunk size %d: expected error %v, actual %v", chunkSize, expected, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadParallel(b *testing.B) {
+	content := bytes.Repeat(readFixture("blocklist", b), 20)
+	b.Run("sequential", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Read(bytes.NewReader(content)); err != nil {
+				b.Fatalf("unexpected error %v", err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadParallel(bytes.NewReader(content), 0); err != nil {
+				b.Fatalf("unexpected error %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"io"
+	"strings"
+)
+
+// A LineError is an error of a specific line.
+type LineError struct {
+	// Line is the 1-based line number.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// A Scanner reads a hosts file line by line without holding more than the current line in memory,
+// lines may be of any length. Scanning stops at the end of the input, at the first read error
+// or at the first invalid line:
+//
+//  scanner := parser.NewScanner(reader)
+//  for scanner.Scan() {
+//    if entry := scanner.Line().Entry(); entry != nil {
+//      ...
+//    }
+//  }
+//  if err := scanner.Err(); err != nil {
+//    ...
+//  }
+type Scanner struct {
+	reader     *bufio.Reader
+	origin     hosts.Origin
+	line       *Line
+	number     int
+	offset     int64
+	lineOffset int64
+	err        error
+}
+
+// NewScanner returns a Scanner which reads from the given io.Reader.
+func NewScanner(reader io.Reader) *Scanner {
+	return NewScannerWithOrigin(reader, hosts.Origin{})
+}
+
+// NewScannerWithOrigin returns a Scanner like NewScanner, which records the given origin
+// together with the line number for every host name.
+func NewScannerWithOrigin(reader io.Reader, origin hosts.Origin) *Scanner {
+	return &Scanner{reader: bufio.NewReader(reader), origin: origin}
+}
+
+// Scan advances to the next line, which is then available by Line. It returns false at the end of the input
+// or when an error occurred, which is then returned by Err.
+func (s *Scanner) Scan() bool {
+	s.line = nil
+	if s.err != nil {
+		return false
+	}
+
+	raw, err := s.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || raw == "") {
+		if err != io.EOF {
+			s.err = err
+		}
+		return false
+	}
+	s.number++
+	s.lineOffset = s.offset
+	s.offset += int64(len(raw))
+
+	line, err := readLine(strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r"))
+	if err != nil {
+		s.err = &LineError{Line: s.number, Err: err}
+		return false
+	}
+	line.number = s.number
+	if line.entry != nil {
+		setOrigin(line.entry, s.origin, s.number)
+	}
+	s.line = line
+	return true
+}
+
+// Line returns the line read by the last call of Scan, nil if Scan returned false.
+func (s *Scanner) Line() *Line {
+	return s.line
+}
+
+// Offset returns the byte offset of the current line in the input.
+func (s *Scanner) Offset() int64 {
+	return s.lineOffset
+}
+
+// Err returns the first error which occurred, a *LineError for an invalid line. It returns nil at the end of the input.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// unwrapLineError returns the error of the line for a *LineError, so Read and ReadDocument keep returning
+// the plain InvalidLineError.
+func unwrapLineError(err error) error {
+	if lineError, ok := err.(*LineError); ok {
+		return lineError.Err
+	}
+	return err
+}
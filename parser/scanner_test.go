@@ -0,0 +1,155 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	reader := strings.NewReader("# vpn\r\n10.8.0.1 vpn.internal\n\n# 10.8.0.2 git.internal\n10.8.0.3 last.internal")
+
+	scanner := NewScanner(reader)
+	expected := []struct {
+		raw      string
+		offset   int64
+		hostName string
+		disabled bool
+	}{
+		{raw: "# vpn", offset: 0},
+		{raw: "10.8.0.1 vpn.internal", offset: 7, hostName: "vpn.internal"},
+		{raw: "", offset: 29},
+		{raw: "# 10.8.0.2 git.internal", offset: 30, hostName: "git.internal", disabled: true},
+		{raw: "10.8.0.3 last.internal", offset: 54, hostName: "last.internal"},
+	}
+	for i, test := range expected {
+		if !scanner.Scan() {
+			t.Fatalf("expected line %d, got end of input with error %v", i+1, scanner.Err())
+		}
+		line := scanner.Line()
+		if line.Number() != i+1 || line.Raw() != test.raw || scanner.Offset() != test.offset {
+			t.Errorf("expected line %d %q at %d, actual line %d %q at %d",
+				i+1, test.raw, test.offset, line.Number(), line.Raw(), scanner.Offset())
+		}
+		if test.hostName == "" {
+			if line.Entry() != nil {
+				t.Errorf("expected no entry for line %d, actual %v", i+1, line.Entry())
+			}
+			continue
+		}
+		if line.Entry() == nil || !line.Entry().Contains(test.hostName) || line.Disabled() != test.disabled {
+			t.Errorf("expected entry of %s (disabled %v) for line %d, actual %v", test.hostName, test.disabled, i+1, line.Entry())
+		}
+	}
+	if scanner.Scan() || scanner.Line() != nil {
+		t.Errorf("expected end of input, actual %v", scanner.Line())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestScanner_LongLine(t *testing.T) {
+	hostNames := make([]string, 0)
+	for i := 0; len(strings.Join(hostNames, " ")) < 256<<10; i++ {
+		hostNames = append(hostNames, "host"+strings.Repeat("x", 20)+string(rune('a'+i%26))+".local")
+	}
+	content := "10.0.0.1 " + strings.Join(hostNames, " ") + "\n10.0.0.2 next.local\n"
+
+	entrySet, err := Read(strings.NewReader(content))
+	assertNoError(err, t)
+
+	if names, ok := entrySet.EntriesOfIP(net.ParseIP("10.0.0.1")); !ok || len(names) == 0 {
+		t.Errorf("expected host names of the long line, actual %v", names)
+	}
+	if _, ok := entrySet.LookupHost("next.local"); !ok {
+		t.Errorf("expected next.local after the long line")
+	}
+}
+
+func TestScanner_InvalidLine(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("10.0.0.1 api.local\n10.0.0.1\n10.0.0.2 web.local\n"))
+	for scanner.Scan() {
+	}
+
+	var lineError *LineError
+	if !errors.As(scanner.Err(), &lineError) || lineError.Line != 2 || !errors.Is(scanner.Err(), InvalidLineError) {
+		t.Errorf("expected invalid line 2, actual %v", scanner.Err())
+	}
+}
+
+type failingReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		return n, r.err
+	}
+	return n, err
+}
+
+func TestScanner_ReadError(t *testing.T) {
+	readError := errors.New("device not ready")
+	reader := &failingReader{reader: bytes.NewBufferString("10.0.0.1 api.local\n"), err: readError}
+
+	scanner := NewScanner(reader)
+	if !scanner.Scan() || !scanner.Line().Entry().Contains("api.local") {
+		t.Fatalf("expected the first line, actual error %v", scanner.Err())
+	}
+	if scanner.Scan() || scanner.Err() != readError {
+		t.Errorf("expected error %v, actual %v", readError, scanner.Err())
+	}
+
+	reader = &failingReader{reader: bytes.NewBufferString("10.0.0.1 api.local\n"), err: readError}
+	if _, err := Read(reader); err != readError {
+		t.Errorf("expected Read to return %v, actual %v", readError, err)
+	}
+}
+
+func TestScanner_StopEarly(t *testing.T) {
+	content := strings.Repeat("10.0.0.1 api.local\n", 1000)
+	reader := strings.NewReader(content)
+
+	scanner := NewScanner(reader)
+	count := 0
+	for scanner.Scan() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 || scanner.Line().Number() != 3 {
+		t.Errorf("expected to stop at line 3, actual %d", count)
+	}
+	if reader.Len() == 0 {
+		t.Errorf("expected the rest of the input to be unread")
+	}
+}
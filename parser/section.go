@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"strings"
+)
+
+// Managed sections are enclosed by marker comments, e.g.
+//
+//   # BEGIN staging
+//   10.0.0.1 api.local
+//   # END staging
+const (
+	sectionBegin = commentSign + " BEGIN "
+	sectionEnd   = commentSign + " END "
+)
+
+// Sections returns the names of all managed sections of the document in order.
+func (d *Document) Sections() []string {
+	names := make([]string, 0)
+	for _, line := range d.lines {
+		if name, ok := sectionName(line.raw, sectionBegin); ok {
+			if _, _, found := d.findSection(name); found {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Section returns the lines between the markers of the named section as a new Document.
+func (d *Document) Section(name string) (section *Document, ok bool) {
+	begin, end, ok := d.findSection(name)
+	if !ok {
+		return nil, false
+	}
+	section = NewDocument()
+	section.lines = append(section.lines, d.lines[begin+1:end]...)
+	return section, true
+}
+
+// SetSection replaces the content of the named section by the lines of the given document.
+// A new section is appended to the end of the document.
+func (d *Document) SetSection(name string, section *Document) {
+	defer d.record("set section "+name, d.snapshot())
+	content := make([]*Line, 0, len(section.lines)+2)
+	content = append(content, &Line{raw: sectionBegin + name})
+	content = append(content, section.lines...)
+	content = append(content, &Line{raw: sectionEnd + name})
+
+	begin, end, ok := d.findSection(name)
+	if !ok {
+		d.lines = append(d.lines, content...)
+		return
+	}
+
+	lines := make([]*Line, 0, len(d.lines)-(end-begin+1)+len(content))
+	lines = append(lines, d.lines[:begin]...)
+	lines = append(lines, content...)
+	lines = append(lines, d.lines[end+1:]...)
+	d.lines = lines
+}
+
+// RemoveSection removes the named section including its markers.
+func (d *Document) RemoveSection(name string) bool {
+	begin, end, ok := d.findSection(name)
+	if !ok {
+		return false
+	}
+	defer d.record("remove section "+name, d.snapshot())
+	d.lines = append(d.lines[:begin], d.lines[end+1:]...)
+	return true
+}
+
+// findSection returns the positions of the begin and end marker of the named section.
+func (d *Document) findSection(name string) (begin int, end int, ok bool) {
+	begin = -1
+	for i, line := range d.lines {
+		if n, isBegin := sectionName(line.raw, sectionBegin); isBegin && n == name && begin < 0 {
+			begin = i
+		}
+		if n, isEnd := sectionName(line.raw, sectionEnd); isEnd && n == name && begin >= 0 {
+			return begin, i, true
+		}
+	}
+	return -1, -1, false
+}
+
+func sectionName(raw string, marker string) (string, bool) {
+	trimmedLine := TrimWhitespace(raw)
+	if !strings.HasPrefix(trimmedLine, marker) {
+		return "", false
+	}
+	return TrimWhitespace(trimmedLine[len(marker):]), true
+}
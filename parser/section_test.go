@@ -0,0 +1,86 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const sectionContent = `127.0.0.1 localhost
+# BEGIN local
+127.0.0.1 api.local
+# END local
+# BEGIN broken
+`
+
+func TestDocument_Sections(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(sectionContent))
+	assertNoError(err, t)
+
+	if sections := doc.Sections(); !reflect.DeepEqual(sections, []string{"local"}) {
+		t.Errorf("expected sections [local], actual %v", sections)
+	}
+
+	section, ok := doc.Section("local")
+	if !ok || section.String() != "127.0.0.1 api.local\n" {
+		t.Errorf("unexpected section %v", section)
+	}
+
+	if _, ok := doc.Section("broken"); ok {
+		t.Errorf("expected a section without end marker to be ignored")
+	}
+}
+
+func TestDocument_SetSection(t *testing.T) {
+	doc, err := ReadDocument(bytes.NewBufferString(sectionContent))
+	assertNoError(err, t)
+
+	staging, err := ReadDocument(bytes.NewBufferString("10.0.0.1 api.local\n"))
+	assertNoError(err, t)
+
+	doc.SetSection("local", staging)
+	doc.SetSection("staging", staging)
+
+	expected := `127.0.0.1 localhost
+# BEGIN local
+10.0.0.1 api.local
+# END local
+# BEGIN broken
+# BEGIN staging
+10.0.0.1 api.local
+# END staging
+`
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+
+	if !doc.RemoveSection("local") || doc.RemoveSection("local") {
+		t.Errorf("expected section 'local' to be removed once")
+	}
+	expected = "127.0.0.1 localhost\n# BEGIN broken\n# BEGIN staging\n10.0.0.1 api.local\n# END staging\n"
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+}
@@ -0,0 +1,157 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data a template of WriteTemplate is executed with.
+type TemplateData struct {
+	// Entries are the entries of the EntrySet in its order.
+	Entries []hosts.Entry
+}
+
+// TemplateFuncs returns the helper functions available in the templates of WriteTemplate:
+//
+//  family IP                   "ipv4" or "ipv6"
+//  isIPv4 IP, isIPv6 IP        whether the ip is of the family
+//  isLoopback IP               whether the ip is a loopback ip
+//  reverse IP                  the reverse DNS name, e.g. "1.0.0.10.in-addr.arpa."
+//  metadata KEY ENTRY          the metadata value of the entry's comment, "" if missing
+//  sortByIP ENTRIES            the entries ordered by hosts.CompareIPs
+//  sortByHostName ENTRIES      the entries ordered by their first host name
+//  ipv4 ENTRIES, ipv6 ENTRIES  the entries of the family
+//  withTag TAG ENTRIES         the entries with the tag
+//  matching PATTERN ENTRIES    the entries with a host name matching the shell pattern, e.g. "*.local"
+//  join SEPARATOR STRINGS      the strings joined by the separator
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"family":         ipFamily,
+		"isIPv4":         func(ip net.IP) bool { return ip.To4() != nil },
+		"isIPv6":         func(ip net.IP) bool { return ip.To4() == nil },
+		"isLoopback":     func(ip net.IP) bool { return ip.IsLoopback() },
+		"reverse":        reverseName,
+		"metadata":       metadataValue,
+		"sortByIP":       sortByIP,
+		"sortByHostName": sortByHostName,
+		"ipv4":           func(entries []hosts.Entry) []hosts.Entry { return filterEntries(entries, isIPv4Entry) },
+		"ipv6": func(entries []hosts.Entry) []hosts.Entry {
+			return filterEntries(entries, func(entry hosts.Entry) bool { return !isIPv4Entry(entry) })
+		},
+		"withTag":  withTag,
+		"matching": matching,
+		"join":     func(separator string, values []string) string { return strings.Join(values, separator) },
+	}
+}
+
+// WriteTemplate executes the text/template text with the TemplateData of the EntrySet and writes the output
+// into the io.Writer. The functions of TemplateFuncs are available in the template.
+func WriteTemplate(entrySet hosts.EntrySet, writer io.Writer, text string) error {
+	tmpl, err := template.New("hosts").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, TemplateData{Entries: entrySet.AllEntries()})
+}
+
+func ipFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+// reverseName returns the name of the PTR record of the ip.
+func reverseName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+	const digits = "0123456789abcdef"
+	ip16 := ip.To16()
+	name := make([]byte, 0, len(ip16)*4+len("ip6.arpa."))
+	for i := len(ip16) - 1; i >= 0; i-- {
+		name = append(name, digits[ip16[i]&0x0f], '.', digits[ip16[i]>>4], '.')
+	}
+	return string(append(name, "ip6.arpa."...))
+}
+
+func metadataValue(key string, entry hosts.Entry) string {
+	value, _ := entry.Metadata(key)
+	return value
+}
+
+func sortByIP(entries []hosts.Entry) []hosts.Entry {
+	sorted := append([]hosts.Entry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return hosts.CompareIPs(sorted[i].Ip(), sorted[j].Ip()) < 0
+	})
+	return sorted
+}
+
+func sortByHostName(entries []hosts.Entry) []hosts.Entry {
+	sorted := append([]hosts.Entry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return firstHostName(sorted[i]) < firstHostName(sorted[j])
+	})
+	return sorted
+}
+
+func isIPv4Entry(entry hosts.Entry) bool {
+	return entry.Ip().To4() != nil
+}
+
+func withTag(tag string, entries []hosts.Entry) []hosts.Entry {
+	return filterEntries(entries, func(entry hosts.Entry) bool { return entry.HasTag(tag) })
+}
+
+func matching(pattern string, entries []hosts.Entry) ([]hosts.Entry, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return filterEntries(entries, func(entry hosts.Entry) bool {
+		for _, hostName := range entry.HostNames() {
+			if matched, _ := path.Match(pattern, hostName); matched {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func filterEntries(entries []hosts.Entry, keep func(entry hosts.Entry) bool) []hosts.Entry {
+	filtered := make([]hosts.Entry, 0)
+	for _, entry := range entries {
+		if keep(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
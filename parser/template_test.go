@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts"
+	"net"
+	"testing"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	entrySet := hosts.NewEntrySet()
+	web := hosts.NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"web.local"})
+	_ = web.AddTag("backend")
+	_ = web.SetMetadata("port", "8080")
+	entrySet.AddEntry(
+		web,
+		hosts.NewEntryUnsafe(net.ParseIP("2001:db8::1"), []string{"api.example.com"}),
+		hosts.NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "app.local"}),
+		hosts.NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}))
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "ssh config",
+			text:     "{{range sortByHostName .Entries}}Host {{join \" \" .HostNames}}\n  HostName {{.Ip}}\n{{end}}",
+			expected: "Host api.example.com\n  HostName 2001:db8::1\nHost api.local app.local\n  HostName 10.0.0.1\nHost localhost\n  HostName 127.0.0.1\nHost web.local\n  HostName 10.0.0.2\n",
+		},
+		{
+			name:     "nginx upstream",
+			text:     "upstream backend {\n{{range .Entries | withTag \"backend\"}}  server {{.Ip}}:{{metadata \"port\" .}};\n{{end}}}\n",
+			expected: "upstream backend {\n  server 10.0.0.2:8080;\n}\n",
+		},
+		{
+			name:     "reverse names",
+			text:     "{{range sortByIP .Entries}}{{if not (isLoopback .Ip)}}{{family .Ip}} {{reverse .Ip}}\n{{end}}{{end}}",
+			expected: "ipv4 1.0.0.10.in-addr.arpa.\nipv4 2.0.0.10.in-addr.arpa.\nipv6 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.\n",
+		},
+		{
+			name:     "filters",
+			text:     "{{range matching \"*.local\" (ipv4 .Entries)}}{{index .HostNames 0}} {{end}}{{len (ipv6 .Entries)}}",
+			expected: "web.local api.local 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			if err := WriteTemplate(entrySet, buffer, test.text); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if buffer.String() != test.expected {
+				t.Errorf("expected '%s' actual '%s'", test.expected, buffer.String())
+			}
+		})
+	}
+
+	if err := WriteTemplate(entrySet, &bytes.Buffer{}, "{{range matching \"[\" .Entries}}{{end}}"); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
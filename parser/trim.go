@@ -22,20 +22,24 @@
 
 package parser
 
-import (
-	"regexp"
-)
-
-var regexpTrimWhitespace = regexp.MustCompile(`^(\s*)(\S+(\s+\S+)*)+(\s*)$`)
-
 // TrimWhitespace removes all whitespaces ([\t\n\f\r ]) from the beginning and end of the given string.
+// It does not allocate.
 func TrimWhitespace(oneLine string) string {
-	if oneLine == "" {
-		return oneLine
+	start, end := 0, len(oneLine)
+	for start < end && isSpace(oneLine[start]) {
+		start++
+	}
+	for end > start && isSpace(oneLine[end-1]) {
+		end--
 	}
-	matches := regexpTrimWhitespace.FindStringSubmatch(oneLine)
-	if matches != nil && len(matches) >= 3 {
-		return matches[2]
+	return oneLine[start:end]
+}
+
+// isSpace reports whether the given byte is a whitespace as matched by \s in regular expressions.
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\f', '\r':
+		return true
 	}
-	return ""
+	return false
 }
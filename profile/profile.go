@@ -0,0 +1,161 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+/*
+Package profile manages named sets of overrides which can be activated in a hosts file.
+
+A profile is a hosts fragment stored as <name>.hosts in the directory of a Store.
+Activating a profile copies the fragment into a managed section of the hosts file:
+
+  # BEGIN hosts profile staging
+  10.0.0.1 api.local
+  # END hosts profile staging
+
+Several profiles can be active at the same time, as long as they do not map a host name to different ips.
+*/
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bitofcode/hosts"
+	"github.com/bitofcode/hosts/hostsfile"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extension is the file extension of profiles in a Store.
+const Extension = ".hosts"
+
+const sectionPrefix = "hosts profile "
+
+var (
+	ErrorInvalidName = errors.New("invalid profile name")
+	ErrorNotActive   = errors.New("profile is not active")
+)
+
+// ConflictError is returned when a profile maps host names to other ips than an already active profile.
+type ConflictError struct {
+	Profile   string
+	Other     string
+	Conflicts []hosts.Conflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("profile '%s' conflicts with active profile '%s': %v",
+		e.Profile, e.Other, &hosts.ConflictError{Conflicts: e.Conflicts})
+}
+
+// A Store is a directory of named hosts fragments.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns the Store of the given directory.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// List returns the sorted names of all profiles in the store.
+func (s *Store) List() ([]string, error) {
+	files, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), Extension) {
+			names = append(names, strings.TrimSuffix(file.Name(), Extension))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load reads the named profile.
+func (s *Store) Load(name string) (*parser.Document, error) {
+	if !isValidName(name) {
+		return nil, ErrorInvalidName
+	}
+	return hostsfile.ReadDocument(filepath.Join(s.Dir, name+Extension))
+}
+
+// Active returns the names of all profiles which are active in the given document.
+func Active(doc *parser.Document) []string {
+	names := make([]string, 0)
+	for _, section := range doc.Sections() {
+		if strings.HasPrefix(section, sectionPrefix) {
+			names = append(names, strings.TrimPrefix(section, sectionPrefix))
+		}
+	}
+	return names
+}
+
+// IsActive reports whether the named profile is active in the given document.
+func IsActive(doc *parser.Document, name string) bool {
+	_, ok := doc.Section(sectionPrefix + name)
+	return ok
+}
+
+// Activate writes the named profile into its managed section of the given document,
+// an already active profile is updated. A *ConflictError is returned if the profile maps
+// a host name to another ip than one of the other active profiles.
+func (s *Store) Activate(doc *parser.Document, name string) error {
+	profile, err := s.Load(name)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range Active(doc) {
+		if other == name {
+			continue
+		}
+		section, _ := doc.Section(sectionPrefix + other)
+		if conflicts := findConflicts(profile, section); len(conflicts) > 0 {
+			return &ConflictError{Profile: name, Other: other, Conflicts: conflicts}
+		}
+	}
+
+	doc.SetSection(sectionPrefix+name, profile)
+	return nil
+}
+
+// Deactivate removes the managed section of the named profile from the given document.
+func Deactivate(doc *parser.Document, name string) error {
+	if !doc.RemoveSection(sectionPrefix + name) {
+		return ErrorNotActive
+	}
+	return nil
+}
+
+// findConflicts returns the conflicts which only exist when both documents are combined.
+func findConflicts(profile *parser.Document, other *parser.Document) []hosts.Conflict {
+	return hosts.Merge(profile.EntrySet(), other.EntrySet())
+}
+
+func isValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\ \t\n\r")
+}
@@ -0,0 +1,154 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package profile
+
+import (
+	"bytes"
+	"github.com/bitofcode/hosts/parser"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestStore(t *testing.T) (store *Store, cleanup func()) {
+	dir, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	profiles := map[string]string{
+		"local":      "127.0.0.1 api.local web.local\n",
+		"staging":    "# staging cluster\n10.0.0.1 api.local\n",
+		"monitoring": "10.0.0.9 grafana.local\n",
+	}
+	for name, content := range profiles {
+		if err := ioutil.WriteFile(filepath.Join(dir, name+Extension), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a profile"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return NewStore(dir), func() { os.RemoveAll(dir) }
+}
+
+func readDocument(content string, t *testing.T) *parser.Document {
+	doc, err := parser.ReadDocument(bytes.NewBufferString(content))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return doc
+}
+
+func TestStore_List(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	names, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []string{"local", "monitoring", "staging"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, actual %v", expected, names)
+	}
+}
+
+func TestStore_LoadInvalidName(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	for _, name := range []string{"", "..", "../local", "my profile"} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load(name); err != ErrorInvalidName {
+				t.Errorf("expected error '%v', actual '%v'", ErrorInvalidName, err)
+			}
+		})
+	}
+}
+
+func TestStore_ActivateDeactivate(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	doc := readDocument("127.0.0.1 localhost\n", t)
+	if err := store.Activate(doc, "staging"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := store.Activate(doc, "monitoring"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := store.Activate(doc, "staging"); err != nil {
+		t.Fatalf("unexpected error when activating twice %v", err)
+	}
+
+	expected := `127.0.0.1 localhost
+# BEGIN hosts profile staging
+# staging cluster
+10.0.0.1 api.local
+# END hosts profile staging
+# BEGIN hosts profile monitoring
+10.0.0.9 grafana.local
+# END hosts profile monitoring
+`
+	if doc.String() != expected {
+		t.Errorf("expected '%s' actual '%s'", expected, doc.String())
+	}
+	if active := Active(doc); !reflect.DeepEqual(active, []string{"staging", "monitoring"}) {
+		t.Errorf("unexpected active profiles %v", active)
+	}
+
+	if err := Deactivate(doc, "staging"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := Deactivate(doc, "staging"); err != ErrorNotActive {
+		t.Errorf("expected error '%v', actual '%v'", ErrorNotActive, err)
+	}
+	if IsActive(doc, "staging") || !IsActive(doc, "monitoring") {
+		t.Errorf("unexpected active profiles %v", Active(doc))
+	}
+}
+
+func TestStore_ActivateConflict(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	doc := readDocument("127.0.0.1 localhost\n", t)
+	if err := store.Activate(doc, "local"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err := store.Activate(doc, "staging")
+	conflictError, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected a *ConflictError, actual '%v'", err)
+	}
+	if conflictError.Other != "local" || len(conflictError.Conflicts) != 1 ||
+		conflictError.Conflicts[0].HostName != "api.local" {
+		t.Errorf("unexpected conflict %v", conflictError)
+	}
+	if IsActive(doc, "staging") {
+		t.Errorf("expected conflicting profile not to be activated")
+	}
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"sort"
+	"strings"
+)
+
+const tagSeparator = ","
+
+// EntriesWithTag returns all entries of the given EntrySet which are tagged with the given tag.
+func EntriesWithTag(entrySet EntrySet, tag string) []Entry {
+	entries := make([]Entry, 0)
+	for _, entry := range entrySet.AllEntries() {
+		if entry.HasTag(tag) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// AllTags returns the sorted tags of all entries of the given EntrySet.
+func AllTags(entrySet EntrySet) []string {
+	unique := make(map[string]bool)
+	for _, entry := range entrySet.AllEntries() {
+		for _, tag := range entry.Tags() {
+			unique[tag] = true
+		}
+	}
+
+	tags := make([]string, 0, len(unique))
+	for tag := range unique {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func splitTags(value string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(value, tagSeparator) {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isValidTag(tag string) bool {
+	return tag != "" && !strings.Contains(tag, tagSeparator) && isValidMetadataValue(tag)
+}
@@ -0,0 +1,78 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func newTaggedEntry(ip string, hostName string, tags ...string) Entry {
+	entry := NewEntryUnsafe(net.ParseIP(ip), []string{hostName})
+	for _, tag := range tags {
+		if err := entry.AddTag(tag); err != nil {
+			panic(err)
+		}
+	}
+	return entry
+}
+
+func TestEntriesWithTag(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		newTaggedEntry("10.0.0.1", "api.staging", "staging", "billing"),
+		newTaggedEntry("10.0.0.2", "api.dev", "dev"),
+		newTaggedEntry("10.0.0.3", "web.staging", "staging"))
+
+	staging := EntriesWithTag(entries, "staging")
+	if len(staging) != 2 {
+		t.Errorf("expected 2 entries with tag 'staging', actual %v", staging)
+	}
+	for _, entry := range staging {
+		if !entry.HasTag("staging") {
+			t.Errorf("unexpected entry %v", entry)
+		}
+	}
+
+	if len(EntriesWithTag(entries, "unknown")) != 0 {
+		t.Errorf("expected no entries with tag 'unknown'")
+	}
+
+	expectedTags := []string{"billing", "dev", "staging"}
+	if tags := AllTags(entries); !reflect.DeepEqual(tags, expectedTags) {
+		t.Errorf("expected tags %v, actual %v", expectedTags, tags)
+	}
+}
+
+func TestEntrySet_AddEntryMergesTags(t *testing.T) {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		newTaggedEntry("10.0.0.1", "api.staging", "staging"),
+		newTaggedEntry("10.0.0.1", "api.dev", "dev"))
+
+	expectedTags := []string{"dev", "staging"}
+	if tags := entries.AllEntries()[0].Tags(); !reflect.DeepEqual(tags, expectedTags) {
+		t.Errorf("expected tags %v, actual %v", expectedTags, tags)
+	}
+}
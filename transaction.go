@@ -0,0 +1,200 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+var ErrorTransactionDone = errors.New("transaction is already committed or rolled back")
+
+// A Transaction is a view of an EntrySet which buffers all changes until Commit.
+// After Commit or Rollback every change returns ErrorTransactionDone.
+type Transaction interface {
+	ReadOnlyEntrySet
+	AddEntry(entry Entry, entries ...Entry) error
+	// RemoveHostName removes the given host name from all ips and reports whether it was found.
+	RemoveHostName(hostName string) (bool, error)
+	// RemoveIP removes the given ip with all its host names and reports whether it was found.
+	RemoveIP(ip net.IP) (bool, error)
+	// Commit validates all buffered changes as a unit against the current content of the underlying EntrySet
+	// and applies them. A *ConflictError is returned if the changes map a host name to more than one ip of the same
+	// address family, or add an ip to a host name which is already in conflict. The transaction stays open in this case.
+	Commit() error
+	// Rollback discards all buffered changes.
+	Rollback() error
+}
+
+type operationKind int
+
+const (
+	addEntry operationKind = iota
+	removeHostName
+	removeIP
+)
+
+type operation struct {
+	kind     operationKind
+	entry    Entry
+	hostName string
+	ip       net.IP
+}
+
+type transaction struct {
+	underlying MutableEntrySet
+	view       MutableEntrySet
+	operations []operation
+	done       bool
+}
+
+// Begin starts a transaction on the given EntrySet. The transaction reads a snapshot of the EntrySet
+// taken by Begin together with its own changes, the EntrySet itself is unchanged until Commit.
+func Begin(entrySet MutableEntrySet) Transaction {
+	return &transaction{underlying: entrySet, view: copyEntrySet(entrySet)}
+}
+
+func copyEntrySet(entrySet EntrySet) MutableEntrySet {
+	copied := NewEntrySet()
+	for _, entry := range entrySet.AllEntries() {
+		copied.AddEntry(entry)
+	}
+	return copied
+}
+
+func (t *transaction) AddEntry(entry Entry, entries ...Entry) error {
+	if t.done {
+		return ErrorTransactionDone
+	}
+	clones := make([]Entry, 0, len(entries)+1)
+	for _, en := range append([]Entry{entry}, entries...) {
+		clone, err := CloneEntry(en)
+		if err != nil {
+			return err
+		}
+		clones = append(clones, clone)
+	}
+	for _, clone := range clones {
+		t.record(operation{kind: addEntry, entry: clone})
+	}
+	return nil
+}
+
+func (t *transaction) RemoveHostName(hostName string) (bool, error) {
+	if t.done {
+		return false, ErrorTransactionDone
+	}
+	return t.record(operation{kind: removeHostName, hostName: strings.ToLower(hostName)}), nil
+}
+
+func (t *transaction) RemoveIP(ip net.IP) (bool, error) {
+	if t.done {
+		return false, ErrorTransactionDone
+	}
+	return t.record(operation{kind: removeIP, ip: ip}), nil
+}
+
+func (t *transaction) Contains(entry Entry) bool {
+	return t.view.Contains(entry)
+}
+
+func (t *transaction) EntriesOfIP(ip net.IP) (hosts []string, ok bool) {
+	return t.view.EntriesOfIP(ip)
+}
+
+func (t *transaction) LookupHost(hostName string) (ips []net.IP, ok bool) {
+	return t.view.LookupHost(hostName)
+}
+
+func (t *transaction) AllEntries() []Entry {
+	return t.view.AllEntries()
+}
+
+func (t *transaction) Commit() error {
+	if t.done {
+		return ErrorTransactionDone
+	}
+	if conflicts := t.newConflicts(); len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+
+	for _, op := range t.operations {
+		apply(t.underlying, op)
+	}
+	t.finish()
+	return nil
+}
+
+func (t *transaction) Rollback() error {
+	if t.done {
+		return ErrorTransactionDone
+	}
+	t.finish()
+	return nil
+}
+
+// newConflicts applies the operations to a copy of the current underlying EntrySet and returns its conflicts
+// which do not exist in the underlying EntrySet with the same ips.
+func (t *transaction) newConflicts() []Conflict {
+	known := make(map[string]bool)
+	for _, conflict := range FindConflicts(t.underlying) {
+		known[conflict.key()] = true
+	}
+
+	candidate := copyEntrySet(t.underlying)
+	for _, op := range t.operations {
+		apply(candidate, op)
+	}
+	conflicts := make([]Conflict, 0)
+	for _, conflict := range FindConflicts(candidate) {
+		if !known[conflict.key()] {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+func (t *transaction) record(op operation) bool {
+	t.operations = append(t.operations, op)
+	return apply(t.view, op)
+}
+
+func (t *transaction) finish() {
+	t.done = true
+	t.operations = nil
+	t.view = NewEntrySet()
+}
+
+func apply(entrySet MutableEntrySet, op operation) bool {
+	switch op.kind {
+	case addEntry:
+		entrySet.AddEntry(op.entry)
+		return true
+	case removeHostName:
+		return entrySet.RemoveHostName(op.hostName)
+	case removeIP:
+		return entrySet.RemoveIP(op.ip)
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// MIT License
+//
+// Copyright (c) 2020 Wassim Akachi <wassim@bitofcode.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package hosts
+
+import (
+	"net"
+	"testing"
+)
+
+func newTransactionTestSet() MutableEntrySet {
+	entries := NewEntrySet()
+	entries.AddEntry(
+		NewEntryUnsafe(net.ParseIP("127.0.0.1"), []string{"localhost"}),
+		NewEntryUnsafe(net.ParseIP("10.0.0.1"), []string{"api.local", "web.local"}))
+	return entries
+}
+
+func TestTransaction_Commit(t *testing.T) {
+	entries := newTransactionTestSet()
+
+	tx := Begin(entries)
+	if err := tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local"})); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if removed, err := tx.RemoveHostName("WEB.local"); !removed || err != nil {
+		t.Errorf("unexpected result of RemoveHostName %v, %v", removed, err)
+	}
+	if removed, err := tx.RemoveHostName("unknown.local"); removed || err != nil {
+		t.Errorf("unexpected result of RemoveHostName %v, %v", removed, err)
+	}
+	if removed, err := tx.RemoveIP(net.ParseIP("127.0.0.1")); !removed || err != nil {
+		t.Errorf("expected 127.0.0.1 to be removed, actual %v, %v", removed, err)
+	}
+
+	if _, ok := tx.LookupHost("db.local"); !ok {
+		t.Errorf("expected transaction to see its own changes")
+	}
+	if _, ok := entries.LookupHost("db.local"); ok {
+		t.Errorf("expected underlying set to be unchanged before commit")
+	}
+	if _, ok := entries.EntriesOfIP(net.ParseIP("127.0.0.1")); !ok {
+		t.Errorf("expected underlying set to be unchanged before commit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, ok := entries.LookupHost("db.local"); !ok {
+		t.Errorf("expected 'db.local' after commit")
+	}
+	if _, ok := entries.LookupHost("web.local"); ok {
+		t.Errorf("expected 'web.local' to be removed after commit")
+	}
+	if _, ok := entries.EntriesOfIP(net.ParseIP("127.0.0.1")); ok {
+		t.Errorf("expected 127.0.0.1 to be removed after commit")
+	}
+
+	if err := tx.Commit(); err != ErrorTransactionDone {
+		t.Errorf("expected error '%v', actual '%v'", ErrorTransactionDone, err)
+	}
+}
+
+func TestTransaction_CommitConflict(t *testing.T) {
+	entries := newTransactionTestSet()
+
+	tx := Begin(entries)
+	tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local", "api.local"}))
+
+	err := tx.Commit()
+	conflictError, ok := err.(*ConflictError)
+	if !ok || len(conflictError.Conflicts) != 1 || conflictError.Conflicts[0].HostName != "api.local" {
+		t.Fatalf("expected a conflict of 'api.local', actual '%v'", err)
+	}
+	if _, ok := entries.LookupHost("db.local"); ok {
+		t.Errorf("expected underlying set to be unchanged after a failed commit")
+	}
+
+	tx.RemoveIP(net.ParseIP("10.0.0.1"))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ips, _ := entries.LookupHost("api.local")
+	if len(ips) != 1 || ips[0].String() != "10.0.0.2" {
+		t.Errorf("expected 'api.local' to be moved to 10.0.0.2, actual %v", ips)
+	}
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	entries := newTransactionTestSet()
+
+	tx := Begin(entries)
+	tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local"}))
+	tx.RemoveIP(net.ParseIP("10.0.0.1"))
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, ok := entries.LookupHost("db.local"); ok {
+		t.Errorf("expected 'db.local' to be discarded")
+	}
+	if _, ok := entries.LookupHost("api.local"); !ok {
+		t.Errorf("expected 'api.local' to be kept")
+	}
+	if err := tx.Rollback(); err != ErrorTransactionDone {
+		t.Errorf("expected error '%v', actual '%v'", ErrorTransactionDone, err)
+	}
+
+	if err := tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local"})); err != ErrorTransactionDone {
+		t.Errorf("expected error '%v', actual '%v'", ErrorTransactionDone, err)
+	}
+	if _, err := tx.RemoveHostName("api.local"); err != ErrorTransactionDone {
+		t.Errorf("expected error '%v', actual '%v'", ErrorTransactionDone, err)
+	}
+	if _, err := tx.RemoveIP(net.ParseIP("10.0.0.1")); err != ErrorTransactionDone {
+		t.Errorf("expected error '%v', actual '%v'", ErrorTransactionDone, err)
+	}
+}
+
+func TestTransaction_CommitValidatesCurrentSet(t *testing.T) {
+	entries := newTransactionTestSet()
+
+	tx := Begin(entries)
+	tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"db.local"}))
+	// changed after Begin, the snapshot of the transaction does not see it
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.3"), []string{"db.local"}))
+
+	err := tx.Commit()
+	conflictError, ok := err.(*ConflictError)
+	if !ok || len(conflictError.Conflicts) != 1 || conflictError.Conflicts[0].HostName != "db.local" {
+		t.Fatalf("expected a conflict of 'db.local', actual '%v'", err)
+	}
+	if hostNames, _ := entries.EntriesOfIP(net.ParseIP("10.0.0.2")); len(hostNames) != 0 {
+		t.Errorf("expected underlying set to be unchanged after a failed commit, actual %v", hostNames)
+	}
+}
+
+func TestTransaction_CommitExistingConflict(t *testing.T) {
+	entries := newTransactionTestSet()
+	entries.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.2"), []string{"api.local"}))
+
+	tx := Begin(entries)
+	tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.5"), []string{"db.local"}))
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("expected an unchanged existing conflict to be accepted, actual %v", err)
+	}
+
+	tx = Begin(entries)
+	tx.AddEntry(NewEntryUnsafe(net.ParseIP("10.0.0.3"), []string{"api.local"}))
+	err := tx.Commit()
+	if conflictError, ok := err.(*ConflictError); !ok || len(conflictError.Conflicts) != 1 ||
+		len(conflictError.Conflicts[0].IPs) != 3 {
+		t.Fatalf("expected the conflict of 'api.local' with a third ip, actual '%v'", err)
+	}
+}